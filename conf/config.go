@@ -4,12 +4,25 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
+const (
+	MinimalPlaybackSpeed        = 0.1
+	MaximalPlaybackSpeed        = 100
+	AsFastAsPossibleSpeed       = "max"
+	AsFastAsPossibleFactor      = 0
+	DefaultKeyframeInterval     = 1000
+	PlaybackRequestTimeout      = time.Second
+	RewindTimeout               = time.Second
+	DefaultWriteRejectException = 1
+)
+
 type (
 	DumpSocketData struct {
 		HostAddress string
@@ -32,6 +45,30 @@ type (
 		OneTimeEmulation          bool
 		DumpTimeLocation          string
 		SimultaneouslyEmulation   bool
+		ParsedHistoryCachePath    string
+		HistoryStorePath          string
+		DumpFormat                string
+		DatabasePath              string
+		WorkMode                  string
+		ExportFormat              string
+		ExportFilePath            string
+		RecordFilePath            string
+		CompareDumpFilePath       string
+		ReportFormat              string
+		ReportFilePath            string
+		RegisterMapPath           string
+		PlaybackSpeed             string
+		MaxGapTime                time.Duration
+		IsGapCompressionLogged    bool
+		KeyframeInterval          int
+		LoopInTime                string
+		LoopOutTime               string
+		ResetPolicy               string
+		IsPrefillEnabled          bool
+		PlaybackMode              string
+		IsVerificationEnabled     bool
+		WritePolicy               string
+		WriteRejectException      int
 		DumpConfig                []DumpSocketsConfigData `toml:"DumpConfig"`
 	}
 )
@@ -48,6 +85,30 @@ var (
 	OneTimeEmulation          bool
 	SimultaneouslyEmulation   bool
 	DumpTimeLocation          *time.Location
+	ParsedHistoryCachePath    string
+	HistoryStorePath          string
+	DumpFormat                string
+	DatabasePath              string
+	WorkMode                  string
+	ExportFormat              string
+	ExportFilePath            string
+	RecordFilePath            string
+	CompareDumpFilePath       string
+	ReportFormat              string
+	ReportFilePath            string
+	RegisterMapPath           string
+	PlaybackSpeed             float64
+	MaxGapTime                time.Duration
+	IsGapCompressionLogged    bool
+	KeyframeInterval          int
+	LoopInTime                string
+	LoopOutTime               string
+	ResetPolicy               string
+	IsPrefillEnabled          bool
+	PlaybackMode              string
+	IsVerificationEnabled     bool
+	WritePolicy               string
+	WriteRejectException      uint8
 
 	Functions = struct {
 		CoilsRead          uint16
@@ -75,6 +136,124 @@ var (
 		RTUOverTCP: "rtu_over_tcp",
 		TCP:        "tcp",
 	}
+	ObjectTypes = struct {
+		Coils string
+		DI    string
+		HR    string
+		IR    string
+	}{
+		Coils: "coils",
+		DI:    "DI",
+		HR:    "HR",
+		IR:    "IR",
+	}
+	DumpFormats = struct {
+		Pcap   string
+		SQLite string
+		CSV    string
+		JSON   string
+	}{
+		Pcap:   "pcap",
+		SQLite: "sqlite",
+		CSV:    "csv",
+		JSON:   "json",
+	}
+	WorkModes = struct {
+		Emulation string
+		Export    string
+		Diff      string
+		Analyze   string
+		Infer     string
+	}{
+		Emulation: "emulation",
+		Export:    "export",
+		Diff:      "diff",
+		Analyze:   "analyze",
+		Infer:     "infer",
+	}
+	BreakpointKinds = struct {
+		TransactionIndex string
+		Timepoint        string
+		Function         string
+		Register         string
+	}{
+		TransactionIndex: "transaction_index",
+		Timepoint:        "timepoint",
+		Function:         "function",
+		Register:         "register",
+	}
+	PlaybackModes = struct {
+		Time    string
+		Request string
+	}{
+		Time:    "time",
+		Request: "request",
+	}
+	ResetPolicies = struct {
+		Keep     string
+		Zero     string
+		Snapshot string
+	}{
+		Keep:     "keep",
+		Zero:     "zero",
+		Snapshot: "snapshot",
+	}
+	WritePolicies = struct {
+		Keep   string
+		Dump   string
+		Reject string
+	}{
+		Keep:   "keep",
+		Dump:   "dump",
+		Reject: "reject",
+	}
+	SeekModes = struct {
+		TransactionIndex string
+		Offset           string
+		Percentage       string
+		Position         string
+	}{
+		TransactionIndex: "transaction_index",
+		Offset:           "offset",
+		Percentage:       "percentage",
+		Position:         "position",
+	}
+	SeekPositions = struct {
+		Start string
+		End   string
+	}{
+		Start: "start",
+		End:   "end",
+	}
+	DeviationKinds = struct {
+		Order      string
+		Values     string
+		Unexpected string
+	}{
+		Order:      "order",
+		Values:     "values",
+		Unexpected: "unexpected",
+	}
+	ReportFormats = struct {
+		Text     string
+		JSON     string
+		Markdown string
+		HTML     string
+	}{
+		Text:     "text",
+		JSON:     "json",
+		Markdown: "markdown",
+		HTML:     "html",
+	}
+	ExportFormats = struct {
+		CSV    string
+		JSONL  string
+		Influx string
+	}{
+		CSV:    "csv",
+		JSONL:  "jsonl",
+		Influx: "influx",
+	}
 	GenFileName   = "result_config.toml"
 	GenFileTitles = struct {
 		ServerDefaultEmulateHost  string
@@ -87,6 +266,30 @@ var (
 		OneTimeEmulation          string
 		DumpTimeLocation          string
 		SimultaneouslyEmulation   string
+		ParsedHistoryCachePath    string
+		HistoryStorePath          string
+		DumpFormat                string
+		DatabasePath              string
+		WorkMode                  string
+		ExportFormat              string
+		ExportFilePath            string
+		RecordFilePath            string
+		CompareDumpFilePath       string
+		ReportFormat              string
+		ReportFilePath            string
+		RegisterMapPath           string
+		PlaybackSpeed             string
+		MaxGapTime                string
+		IsGapCompressionLogged    string
+		KeyframeInterval          string
+		LoopInTime                string
+		LoopOutTime               string
+		ResetPolicy               string
+		IsPrefillEnabled          string
+		PlaybackMode              string
+		IsVerificationEnabled     string
+		WritePolicy               string
+		WriteRejectException      string
 		DumpConfig                struct {
 			Title string
 			DumpSocketsConfigData
@@ -102,6 +305,30 @@ var (
 		OneTimeEmulation:          "OneTimeEmulation",
 		DumpTimeLocation:          "DumpTimeLocation",
 		SimultaneouslyEmulation:   "SimultaneouslyEmulation",
+		ParsedHistoryCachePath:    "ParsedHistoryCachePath",
+		HistoryStorePath:          "HistoryStorePath",
+		DumpFormat:                "DumpFormat",
+		DatabasePath:              "DatabasePath",
+		WorkMode:                  "WorkMode",
+		ExportFormat:              "ExportFormat",
+		ExportFilePath:            "ExportFilePath",
+		RecordFilePath:            "RecordFilePath",
+		CompareDumpFilePath:       "CompareDumpFilePath",
+		ReportFormat:              "ReportFormat",
+		ReportFilePath:            "ReportFilePath",
+		RegisterMapPath:           "RegisterMapPath",
+		PlaybackSpeed:             "PlaybackSpeed",
+		MaxGapTime:                "MaxGapTime",
+		IsGapCompressionLogged:    "IsGapCompressionLogged",
+		KeyframeInterval:          "KeyframeInterval",
+		LoopInTime:                "LoopInTime",
+		LoopOutTime:               "LoopOutTime",
+		ResetPolicy:               "ResetPolicy",
+		IsPrefillEnabled:          "IsPrefillEnabled",
+		PlaybackMode:              "PlaybackMode",
+		IsVerificationEnabled:     "IsVerificationEnabled",
+		WritePolicy:               "WritePolicy",
+		WriteRejectException:      "WriteRejectException",
 		DumpConfig: struct {
 			Title string
 			DumpSocketsConfigData
@@ -142,6 +369,70 @@ func init() {
 		log.Fatalf("Error on parsing dump time location: %s", err)
 	}
 	SimultaneouslyEmulation = config.SimultaneouslyEmulation
+	ParsedHistoryCachePath = config.ParsedHistoryCachePath
+	HistoryStorePath = config.HistoryStorePath
+	DumpFormat = config.DumpFormat
+	if DumpFormat == "" {
+		DumpFormat = DumpFormats.Pcap
+	}
+	DatabasePath = config.DatabasePath
+	WorkMode = config.WorkMode
+	if WorkMode == "" {
+		WorkMode = WorkModes.Emulation
+	}
+	ExportFormat = config.ExportFormat
+	ExportFilePath = config.ExportFilePath
+	RecordFilePath = config.RecordFilePath
+	CompareDumpFilePath = config.CompareDumpFilePath
+	ReportFormat = config.ReportFormat
+	if ReportFormat == "" {
+		ReportFormat = ReportFormats.Text
+	}
+	ReportFilePath = config.ReportFilePath
+	RegisterMapPath = config.RegisterMapPath
+	if PlaybackSpeed, err = ParsePlaybackSpeed(config.PlaybackSpeed); err != nil {
+		log.Fatalf("Error on parsing playback speed: %s", err)
+	}
+	MaxGapTime = config.MaxGapTime
+	IsGapCompressionLogged = config.IsGapCompressionLogged
+	if KeyframeInterval = config.KeyframeInterval; KeyframeInterval < 1 {
+		KeyframeInterval = DefaultKeyframeInterval
+	}
+	if _, err = ParseLoopTime(config.LoopInTime); err != nil {
+		log.Fatalf("Error on parsing loop-in time: %s", err)
+	}
+	LoopInTime = config.LoopInTime
+	if _, err = ParseLoopTime(config.LoopOutTime); err != nil {
+		log.Fatalf("Error on parsing loop-out time: %s", err)
+	}
+	LoopOutTime = config.LoopOutTime
+	if ResetPolicy = config.ResetPolicy; ResetPolicy == "" {
+		ResetPolicy = ResetPolicies.Keep
+	}
+	if !slices.Contains([]string{ResetPolicies.Keep, ResetPolicies.Zero, ResetPolicies.Snapshot}, ResetPolicy) {
+		log.Fatalf("Error: invalid reset policy: %s", ResetPolicy)
+	}
+	IsPrefillEnabled = config.IsPrefillEnabled
+	if PlaybackMode = config.PlaybackMode; PlaybackMode == "" {
+		PlaybackMode = PlaybackModes.Time
+	}
+	if PlaybackMode != PlaybackModes.Time && PlaybackMode != PlaybackModes.Request {
+		log.Fatalf("Error: invalid playback mode: %s", PlaybackMode)
+	}
+	IsVerificationEnabled = config.IsVerificationEnabled
+	if WritePolicy = config.WritePolicy; WritePolicy == "" {
+		WritePolicy = WritePolicies.Dump
+	}
+	if !slices.Contains([]string{WritePolicies.Keep, WritePolicies.Dump, WritePolicies.Reject}, WritePolicy) {
+		log.Fatalf("Error: invalid write policy: %s", WritePolicy)
+	}
+	if config.WriteRejectException == 0 {
+		config.WriteRejectException = DefaultWriteRejectException
+	}
+	if config.WriteRejectException < 0 || config.WriteRejectException > 255 {
+		log.Fatalf("Error: invalid write reject exception: %d", config.WriteRejectException)
+	}
+	WriteRejectException = uint8(config.WriteRejectException)
 	Sockets = make(map[string]DumpSocketData)
 	if !IsAutoParsingMode {
 		log.Print("Using manually work mode of parsing dump: using configuration list")
@@ -166,3 +457,37 @@ func init() {
 		log.Print("Using automatically work mode of parsing dump")
 	}
 }
+
+func ParsePlaybackSpeed(speed string) (factor float64, err error) {
+	switch speed {
+	case "":
+		return 1, nil
+	case AsFastAsPossibleSpeed:
+		return AsFastAsPossibleFactor, nil
+	}
+	if factor, err = strconv.ParseFloat(speed, 64); err != nil {
+		err = fmt.Errorf("invalid speed %s: must be number or \"%s\"", speed, AsFastAsPossibleSpeed)
+		return
+	}
+	if factor < MinimalPlaybackSpeed || factor > MaximalPlaybackSpeed {
+		err = fmt.Errorf("invalid speed %s: must be in range [%v:%v] or \"%s\"", speed, MinimalPlaybackSpeed, MaximalPlaybackSpeed, AsFastAsPossibleSpeed)
+	}
+	return
+}
+
+func FormatPlaybackSpeed(factor float64) string {
+	if factor == AsFastAsPossibleFactor {
+		return AsFastAsPossibleSpeed
+	}
+	return strconv.FormatFloat(factor, 'f', -1, 64)
+}
+
+func ParseLoopTime(loopTime string) (timepoint time.Time, err error) {
+	if loopTime == "" {
+		return
+	}
+	if timepoint, err = time.ParseInLocation(time.DateTime, loopTime, DumpTimeLocation); err != nil {
+		err = fmt.Errorf("invalid loop time %s: must be in \"%s\" format", loopTime, time.DateTime)
+	}
+	return
+}
@@ -201,22 +201,29 @@ const docTemplate = `{
         }
       }
     },
-    "/time/actual": {
-      "get": {
+    "/settings/playback_speed": {
+      "post": {
         "tags": [
-          "Time"
+          "Settings"
         ],
-        "description": "Get actual time of emulation",
+        "description": "Set playback speed factor of emulation",
         "produces": [
-          "applicaion/json"
+          "application/json"
         ],
         "parameters": [
+          {
+            "name": "speed",
+            "in": "query",
+            "required": true,
+            "type": "string",
+            "description": "Speed factor in range [0.1:100] or \"max\" (as fast as possible)"
+          },
           {
             "name": "server_id",
             "in": "query",
             "required": false,
             "type": "integer",
-            "description": "If parameter == nil -> response consist of data for all servers"
+            "description": "If parameter == nil -> speed will be set for all servers"
           }
         ],
         "responses": {
@@ -225,12 +232,18 @@ const docTemplate = `{
             "schema": {
               "type": "array",
               "items": {
-                "$ref": "#/definitions/ActualTime"
+                "$ref": "#/definitions/ServersData"
               }
             }
           },
+          "400": {
+            "description": "Missed \"speed\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
           "422": {
-            "description": "Invalid \"server_id\" parameter",
+            "description": "Invalid \"speed\" or \"server_id\" parameter",
             "schema": {
               "$ref": "#/definitions/Error"
             }
@@ -238,22 +251,36 @@ const docTemplate = `{
         }
       }
     },
-    "/time/start&end": {
-      "get": {
+    "/settings/loop": {
+      "post": {
         "tags": [
-          "Time"
+          "Settings"
         ],
-        "description": "Get time of first and last operation in the dump",
+        "description": "Set loop segment of continuous emulation (without both parameters loop will be disabled)",
         "produces": [
           "application/json"
         ],
         "parameters": [
+          {
+            "name": "in_time",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "description": "Loop-in time by format: \"yyyy-mm-dd hh:mm:ss\" (start of dump if empty)"
+          },
+          {
+            "name": "out_time",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "description": "Loop-out time by format: \"yyyy-mm-dd hh:mm:ss\" (end of dump if empty)"
+          },
           {
             "name": "server_id",
             "in": "query",
             "required": false,
             "type": "integer",
-            "description": "If parameter == nil -> response consist of data for all servers"
+            "description": "If parameter == nil -> loop segment will be set for all servers"
           }
         ],
         "responses": {
@@ -262,12 +289,12 @@ const docTemplate = `{
             "schema": {
               "type": "array",
               "items": {
-                "$ref": "#/definitions/StartEndTime"
+                "$ref": "#/definitions/ServersData"
               }
             }
           },
           "422": {
-            "description": "Invalid \"server_id\" parameter",
+            "description": "Invalid \"in_time\", \"out_time\" or \"server_id\" parameter",
             "schema": {
               "$ref": "#/definitions/Error"
             }
@@ -275,29 +302,34 @@ const docTemplate = `{
         }
       }
     },
-    "/time/rewind_emulation": {
+    "/settings/reset_policy": {
       "post": {
         "tags": [
-          "Time"
+          "Settings"
         ],
-        "description": "Rewinding emulation time",
+        "description": "Set register reset policy applied at loop boundaries of continuous emulation",
         "produces": [
           "application/json"
         ],
         "parameters": [
           {
-            "name": "timepoint",
+            "name": "policy",
             "in": "query",
             "required": true,
             "type": "string",
-            "description": "Time staring by format: \"yyyy-mm-dd hh:mm:ss(.ms)"
+            "enum": [
+              "keep",
+              "zero",
+              "snapshot"
+            ],
+            "description": "\"keep\" - keep state, \"zero\" - reset registers to zero, \"snapshot\" - restore registers image of loop-in point"
           },
           {
             "name": "server_id",
             "in": "query",
             "required": false,
             "type": "integer",
-            "description": "If parameter == nil -> rewind will done for all servers"
+            "description": "If parameter == nil -> policy will be set for all servers"
           }
         ],
         "responses": {
@@ -306,130 +338,1623 @@ const docTemplate = `{
             "schema": {
               "type": "array",
               "items": {
-                "$ref": "#/definitions/Rewind"
+                "$ref": "#/definitions/ServersData"
               }
             }
           },
+          "400": {
+            "description": "Missed \"policy\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
           "422": {
-            "description": "Invalid \"timepoint\" or \"server_id\" parameter",
+            "description": "Invalid \"policy\" or \"server_id\" parameter",
             "schema": {
               "$ref": "#/definitions/Error"
             }
           }
         }
       }
-    }
-  },
-  "definitions": {
-    "Server": {
-      "type": "object",
-      "properties": {
-        "is_working": {
-          "type": "boolean"
-        },
-        "dump_socket": {
-          "type": "string"
-        },
-        "real_socket": {
-          "type": "string"
-        },
-        "protocol": {
-          "type": "string"
-        },
-        "one_time_emulation": {
-          "type": "boolean"
-        },
-        "start_time": {
-          "type": "string"
-        },
-        "end_time": {
-          "type": "string"
-        },
-        "current_time": {
-          "type": "string"
-        }
-      }
     },
-    "ServersData": {
-      "type": "object",
-      "properties": {
-        "id": {
-          "type": "integer"
-        },
-        "settings": {
-          "type": "array",
-          "items": {
-            "$ref": "#/definitions/Server"
+    "/settings/write_policy": {
+      "post": {
+        "tags": [
+          "Settings"
+        ],
+        "description": "Set policy of handling client writes to registers during emulation",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "policy",
+            "in": "query",
+            "required": true,
+            "type": "string",
+            "enum": [
+              "keep",
+              "dump",
+              "reject"
+            ],
+            "description": "\"keep\" - accept write and keep it over replay, \"dump\" - accept write and let dump overwrite it, \"reject\" - reject write with Modbus exception"
+          },
+          {
+            "name": "exception",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Modbus exception code of rejected writes (default from config)"
+          },
+          {
+            "name": "slave_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> policy will be set for whole server"
+          },
+          {
+            "name": "object_type",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "enum": [
+              "coils",
+              "HR"
+            ],
+            "description": "Required with \"slave_id\" parameter"
+          },
+          {
+            "name": "start",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "First address of range, required with \"slave_id\" parameter"
+          },
+          {
+            "name": "end",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Last address of range, required with \"slave_id\" parameter"
+          },
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> policy will be set for all servers"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ServersData"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"policy\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"policy\", \"exception\", range or \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
-      }
-    },
-    "ActualTime": {
-      "type": "object",
-      "properties": {
-        "id": {
-          "type": "integer"
-        },
-        "actual_time": {
-          "type": "string"
-        }
-      }
-    },
-    "StartEndTime": {
-      "type": "object",
-      "properties": {
-        "id": {
-          "type": "integer"
-        },
-        "start_time": {
-          "type": "string"
-        },
-        "end_time": {
-          "type": "string"
-        }
-      }
-    },
-    "Rewind": {
-      "type": "object",
-      "properties": {
-        "id": {
-          "type": "integer"
-        },
-        "error": {
-          "type": "string"
-        },
-        "setted_timepoint": {
-          "type": "string"
-        }
-      }
-    },
-    "ValidSlaves": {
-      "type": "object",
-      "properties": {
-        "server_id": {
-          "type": "integer"
-        },
-        "answered_slaves": {
-          "type": "array",
-          "items": {
-            "type": "integer"
+      },
+      "delete": {
+        "tags": [
+          "Settings"
+        ],
+        "description": "Delete write policies of register ranges",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> ranges will be deleted for all servers"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ServersData"
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       }
     },
-    "EmulationControl": {
-      "type": "object",
-      "properties": {
-        "id": {
-          "type": "integer"
-        },
-        "error": {
-          "type": "string"
-        },
-        "is_emulating": {
-          "type": "string"
-        }
-      }
+    "/settings/force": {
+      "post": {
+        "tags": [
+          "Settings"
+        ],
+        "description": "Force range of registers to values persisting over replay",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": true,
+            "type": "integer"
+          },
+          {
+            "name": "slave_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Required without \"tag\" parameter"
+          },
+          {
+            "name": "object_type",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "enum": [
+              "coils",
+              "DI",
+              "HR",
+              "IR"
+            ],
+            "description": "Required without \"tag\" parameter"
+          },
+          {
+            "name": "start",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "First address of range, required without \"tag\" parameter"
+          },
+          {
+            "name": "end",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Last address of range, required without \"tag\" parameter"
+          },
+          {
+            "name": "value",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "description": "Forced value of every address of range (0 or 1 for coils and DI) or engineering value of \"tag\" parameter"
+          },
+          {
+            "name": "values",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "description": "Comma-separated forced values of every address of range, used without \"value\" parameter"
+          },
+          {
+            "name": "tag",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "description": "Name of register map tag forced to \"value\" parameter instead of range"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ServersData"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"server_id\" or value parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Tag isn't found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\", range, \"tag\" or values parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "tags": [
+          "Settings"
+        ],
+        "description": "Unforce range of registers",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": true,
+            "type": "integer"
+          },
+          {
+            "name": "slave_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> all forces will be removed"
+          },
+          {
+            "name": "object_type",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "enum": [
+              "coils",
+              "DI",
+              "HR",
+              "IR"
+            ],
+            "description": "Required with \"slave_id\" parameter"
+          },
+          {
+            "name": "start",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "First address of range, required with \"slave_id\" parameter"
+          },
+          {
+            "name": "end",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Last address of range, required with \"slave_id\" parameter"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ServersData"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" or range parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/time/actual": {
+      "get": {
+        "tags": [
+          "Time"
+        ],
+        "description": "Get actual time of emulation",
+        "produces": [
+          "applicaion/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> response consist of data for all servers"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ActualTime"
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/time/start&end": {
+      "get": {
+        "tags": [
+          "Time"
+        ],
+        "description": "Get time of first and last operation in the dump",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> response consist of data for all servers"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/StartEndTime"
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/time/rewind_emulation": {
+      "post": {
+        "tags": [
+          "Time"
+        ],
+        "description": "Rewinding emulation time",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "timepoint",
+            "in": "query",
+            "required": true,
+            "type": "string",
+            "description": "Time staring by format: \"yyyy-mm-dd hh:mm:ss(.ms)"
+          },
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> rewind will done for all servers"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/Rewind"
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid \"timepoint\" or \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "503": {
+            "description": "Server hasn't accepted rewind in time",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/Rewind"
+              }
+            }
+          }
+        }
+      }
+    },
+    "/time/seek": {
+      "post": {
+        "tags": [
+          "Time"
+        ],
+        "description": "Seeking emulation by transaction index, relative offset, percentage of dump or to start/end of dump",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "mode",
+            "in": "query",
+            "required": true,
+            "type": "string",
+            "enum": [
+              "transaction_index",
+              "offset",
+              "percentage",
+              "position"
+            ],
+            "description": "Seek mode"
+          },
+          {
+            "name": "value",
+            "in": "query",
+            "required": true,
+            "type": "string",
+            "description": "Transaction index, relative duration (\"-30s\", \"+5m\") from current transaction, percentage of dump duration [0:100] or position (\"start\", \"end\")"
+          },
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> seek will done for all servers"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/Seek"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed required parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"mode\", \"value\" or \"server_id\" parameter",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/Seek"
+              }
+            }
+          },
+          "503": {
+            "description": "Server hasn't accepted seek in time",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/Seek"
+              }
+            }
+          }
+        }
+      }
+    },
+    "/debug": {
+      "get": {
+        "tags": [
+          "Debug"
+        ],
+        "description": "Get debug state of emulation",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> response contains data about all servers"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/DebugState"
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/debug/pause": {
+      "post": {
+        "tags": [
+          "Debug"
+        ],
+        "description": "Pause emulation before next transaction",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": true,
+            "type": "integer"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/DebugState"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter or emulation isn't initialized",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/debug/step": {
+      "post": {
+        "tags": [
+          "Debug"
+        ],
+        "description": "Execute N transactions without delays and pause again",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": true,
+            "type": "integer"
+          },
+          {
+            "name": "count",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Count of transactions (default 1)"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/DebugState"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" or \"count\" parameter or emulation isn't initialized",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/debug/run": {
+      "post": {
+        "tags": [
+          "Debug"
+        ],
+        "description": "Run emulation until breakpoint",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": true,
+            "type": "integer"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/DebugState"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter or emulation isn't initialized",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/debug/breakpoints": {
+      "post": {
+        "tags": [
+          "Debug"
+        ],
+        "description": "Add breakpoint pausing emulation after matched transaction",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": true,
+            "type": "integer"
+          },
+          {
+            "name": "kind",
+            "in": "query",
+            "required": true,
+            "type": "string",
+            "description": "Must be \"transaction_index\", \"timepoint\", \"function\" or \"register\""
+          },
+          {
+            "name": "transaction_index",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Transaction index (for \"transaction_index\" kind)"
+          },
+          {
+            "name": "timepoint",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "description": "Dump time by format \"yyyy-mm-dd hh:mm:ss\" (for \"timepoint\" kind)"
+          },
+          {
+            "name": "slave_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Slave ID (for \"function\" and \"register\" kinds, 0 -> any slave)"
+          },
+          {
+            "name": "function_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Function ID (for \"function\" kind, 0 -> any function)"
+          },
+          {
+            "name": "object_type",
+            "in": "query",
+            "required": false,
+            "type": "string",
+            "description": "Object type: \"coils\", \"DI\", \"HR\" or \"IR\" (for \"register\" kind)"
+          },
+          {
+            "name": "address",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Register address (for \"register\" kind)"
+          },
+          {
+            "name": "value",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Value which register becomes (for \"register\" kind)"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/DebugState"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"server_id\" or \"kind\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid breakpoint parameters",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "tags": [
+          "Debug"
+        ],
+        "description": "Delete breakpoint",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": true,
+            "type": "integer"
+          },
+          {
+            "name": "breakpoint_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> all breakpoints will be deleted"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/DebugState"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" or \"breakpoint_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/verification": {
+      "get": {
+        "tags": [
+          "Verification"
+        ],
+        "description": "Get verification report of client requests against recorded master requests",
+        "produces": [
+          "application/json"
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/VerificationReport"
+            }
+          },
+          "422": {
+            "description": "Verification isn't enabled",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/write_events": {
+      "get": {
+        "tags": [
+          "Settings"
+        ],
+        "description": "Get event log of client writes (last 1000 writes of each server)",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> events of all servers will be returned"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/WriteEvent"
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/tags": {
+      "get": {
+        "tags": [
+          "Tags"
+        ],
+        "description": "Get engineering values of register map tags",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> response contains tags of all servers"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/TagValue"
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/tags/{name}": {
+      "get": {
+        "tags": [
+          "Tags"
+        ],
+        "description": "Get engineering value of register map tag",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "name",
+            "in": "path",
+            "required": true,
+            "type": "string",
+            "description": "Tag name from register map"
+          },
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> all servers serving the tag are used"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/TagValue"
+              }
+            }
+          },
+          "404": {
+            "description": "Tag isn't found in register map",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"server_id\" parameter or tag isn't served by requested servers",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      },
+      "put": {
+        "tags": [
+          "Tags"
+        ],
+        "description": "Encode engineering value of register map tag to registers of slave",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "name",
+            "in": "path",
+            "required": true,
+            "type": "string",
+            "description": "Tag name from register map"
+          },
+          {
+            "name": "value",
+            "in": "query",
+            "required": true,
+            "type": "string",
+            "description": "Engineering value: number, string, boolean or bitfield (\"0b101\", \"0x5\")"
+          },
+          {
+            "name": "server_id",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "If parameter == nil -> all servers serving the tag are used"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/TagValue"
+              }
+            }
+          },
+          "400": {
+            "description": "Missed \"value\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Tag isn't found in register map",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"value\" or \"server_id\" parameter",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/servers/{id}/slaves/{slave}/{object_type}": {
+      "get": {
+        "tags": [
+          "Registers"
+        ],
+        "description": "Read range of slave registers",
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "type": "integer",
+            "description": "Server ID"
+          },
+          {
+            "name": "slave",
+            "in": "path",
+            "required": true,
+            "type": "integer",
+            "description": "Slave ID"
+          },
+          {
+            "name": "object_type",
+            "in": "path",
+            "required": true,
+            "type": "string",
+            "enum": [
+              "coils",
+              "di",
+              "hr",
+              "ir"
+            ]
+          },
+          {
+            "name": "from",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "First address (default 0)"
+          },
+          {
+            "name": "count",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Count of registers (default 1)"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success",
+            "schema": {
+              "$ref": "#/definitions/Registers"
+            }
+          },
+          "404": {
+            "description": "Server, slave or object type isn't found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"from\" or \"count\" parameter or range is out of bounds",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      },
+      "put": {
+        "tags": [
+          "Registers"
+        ],
+        "description": "Write range of slave registers",
+        "consumes": [
+          "application/json"
+        ],
+        "produces": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "type": "integer",
+            "description": "Server ID"
+          },
+          {
+            "name": "slave",
+            "in": "path",
+            "required": true,
+            "type": "integer",
+            "description": "Slave ID"
+          },
+          {
+            "name": "object_type",
+            "in": "path",
+            "required": true,
+            "type": "string",
+            "enum": [
+              "coils",
+              "di",
+              "hr",
+              "ir"
+            ]
+          },
+          {
+            "name": "from",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "First address (default 0)"
+          },
+          {
+            "name": "count",
+            "in": "query",
+            "required": false,
+            "type": "integer",
+            "description": "Count of registers (default count of payload values)"
+          },
+          {
+            "name": "payload",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/RegistersPayload"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Success, values of range after reapplying kept client writes and forces",
+            "schema": {
+              "$ref": "#/definitions/Registers"
+            }
+          },
+          "400": {
+            "description": "Invalid or empty payload",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Server, slave or object type isn't found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "422": {
+            "description": "Invalid \"from\", \"count\" parameter or payload value, or range is out of bounds",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "Server": {
+      "type": "object",
+      "properties": {
+        "is_working": {
+          "type": "boolean"
+        },
+        "dump_socket": {
+          "type": "string"
+        },
+        "real_socket": {
+          "type": "string"
+        },
+        "protocol": {
+          "type": "string"
+        },
+        "one_time_emulation": {
+          "type": "boolean"
+        },
+        "speed": {
+          "type": "number",
+          "description": "Playback speed factor (0 -> as fast as possible)"
+        },
+        "loop": {
+          "$ref": "#/definitions/LoopSegment"
+        },
+        "reset_policy": {
+          "type": "string"
+        },
+        "write_policy": {
+          "$ref": "#/definitions/WritePolicy"
+        },
+        "forces": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ForcedRange"
+          }
+        },
+        "start_time": {
+          "type": "string"
+        },
+        "end_time": {
+          "type": "string"
+        },
+        "current_time": {
+          "type": "string"
+        }
+      }
+    },
+    "LoopSegment": {
+      "type": "object",
+      "properties": {
+        "is_enabled": {
+          "type": "boolean"
+        },
+        "in_time": {
+          "type": "string"
+        },
+        "out_time": {
+          "type": "string"
+        },
+        "in_index": {
+          "type": "integer"
+        },
+        "out_index": {
+          "type": "integer"
+        },
+        "counter": {
+          "type": "integer",
+          "description": "Count of completed loops"
+        }
+      }
+    },
+    "ServersData": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "integer"
+        },
+        "settings": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Server"
+          }
+        }
+      }
+    },
+    "ActualTime": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "integer"
+        },
+        "actual_time": {
+          "type": "string"
+        }
+      }
+    },
+    "StartEndTime": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "integer"
+        },
+        "start_time": {
+          "type": "string"
+        },
+        "end_time": {
+          "type": "string"
+        }
+      }
+    },
+    "Rewind": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "integer"
+        },
+        "error": {
+          "type": "string"
+        },
+        "setted_timepoint": {
+          "type": "string"
+        }
+      }
+    },
+    "Seek": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "integer"
+        },
+        "error": {
+          "type": "string"
+        },
+        "transaction_index": {
+          "type": "integer"
+        },
+        "timepoint": {
+          "type": "string"
+        }
+      }
+    },
+    "ValidSlaves": {
+      "type": "object",
+      "properties": {
+        "server_id": {
+          "type": "integer"
+        },
+        "answered_slaves": {
+          "type": "array",
+          "items": {
+            "type": "integer"
+          }
+        }
+      }
+    },
+    "EmulationControl": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "integer"
+        },
+        "error": {
+          "type": "string"
+        },
+        "is_emulating": {
+          "type": "string"
+        }
+      }
+    },
+    "Breakpoint": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "integer"
+        },
+        "kind": {
+          "type": "string"
+        },
+        "transaction_index": {
+          "type": "integer"
+        },
+        "timepoint": {
+          "type": "string"
+        },
+        "slave_id": {
+          "type": "integer"
+        },
+        "function_id": {
+          "type": "integer"
+        },
+        "object_type": {
+          "type": "string"
+        },
+        "address": {
+          "type": "integer"
+        },
+        "value": {
+          "type": "integer"
+        }
+      }
+    },
+    "DebugState": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "integer"
+        },
+        "is_emulating": {
+          "type": "boolean"
+        },
+        "debug": {
+          "type": "object",
+          "properties": {
+            "steps_left": {
+              "type": "integer"
+            },
+            "transaction_index": {
+              "type": "integer"
+            },
+            "breakpoints": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/Breakpoint"
+              }
+            },
+            "hit_breakpoint": {
+              "$ref": "#/definitions/Breakpoint"
+            }
+          }
+        }
+      }
+    },
+    "TagValue": {
+      "type": "object",
+      "properties": {
+        "server_id": {
+          "type": "integer"
+        },
+        "name": {
+          "type": "string"
+        },
+        "socket": {
+          "type": "string"
+        },
+        "slave": {
+          "type": "integer"
+        },
+        "object_type": {
+          "type": "string"
+        },
+        "address": {
+          "type": "integer"
+        },
+        "type": {
+          "type": "string"
+        },
+        "byte_order": {
+          "type": "string"
+        },
+        "word_order": {
+          "type": "string"
+        },
+        "length": {
+          "type": "integer"
+        },
+        "scale": {
+          "type": "number"
+        },
+        "offset": {
+          "type": "number"
+        },
+        "unit": {
+          "type": "string"
+        },
+        "counter": {
+          "type": "boolean"
+        },
+        "value": {
+          "description": "Engineering value: number, string or boolean"
+        },
+        "registers": {
+          "type": "array",
+          "items": {
+            "type": "integer"
+          }
+        },
+        "error": {
+          "type": "string"
+        }
+      }
+    },
+    "VerifiedRequest": {
+      "type": "object",
+      "properties": {
+        "slave_id": {
+          "type": "integer"
+        },
+        "function_id": {
+          "type": "integer"
+        },
+        "address": {
+          "type": "integer"
+        },
+        "quantity": {
+          "type": "integer"
+        },
+        "values": {
+          "type": "array",
+          "items": {
+            "type": "integer"
+          }
+        }
+      }
+    },
+    "Deviation": {
+      "type": "object",
+      "properties": {
+        "position": {
+          "type": "integer",
+          "description": "Index of expected request"
+        },
+        "received_time": {
+          "type": "string"
+        },
+        "kind": {
+          "type": "string",
+          "enum": [
+            "order",
+            "values",
+            "unexpected"
+          ]
+        },
+        "expected": {
+          "$ref": "#/definitions/VerifiedRequest"
+        },
+        "received": {
+          "$ref": "#/definitions/VerifiedRequest"
+        },
+        "skipped": {
+          "type": "integer",
+          "description": "Count of skipped expected requests"
+        }
+      }
+    },
+    "ServerVerification": {
+      "type": "object",
+      "properties": {
+        "socket": {
+          "type": "string"
+        },
+        "expected": {
+          "type": "integer"
+        },
+        "received": {
+          "type": "integer"
+        },
+        "matched": {
+          "type": "integer"
+        },
+        "missing": {
+          "type": "integer"
+        },
+        "loops": {
+          "type": "integer"
+        },
+        "deviations_count": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "integer"
+          }
+        },
+        "deviations": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Deviation"
+          }
+        },
+        "is_finished": {
+          "type": "boolean"
+        },
+        "passed": {
+          "type": "boolean"
+        }
+      }
+    },
+    "VerificationReport": {
+      "type": "object",
+      "properties": {
+        "passed": {
+          "type": "boolean"
+        },
+        "is_finished": {
+          "type": "boolean"
+        },
+        "servers": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ServerVerification"
+          }
+        }
+      }
     },
     "Error": {
       "type": "object",
@@ -441,6 +1966,146 @@ const docTemplate = `{
           "type": "string"
         }
       }
+    },
+    "WritePolicyRange": {
+      "type": "object",
+      "properties": {
+        "slave_id": {
+          "type": "integer"
+        },
+        "object_type": {
+          "type": "string"
+        },
+        "start": {
+          "type": "integer"
+        },
+        "end": {
+          "type": "integer"
+        },
+        "policy": {
+          "type": "string"
+        },
+        "exception": {
+          "type": "integer"
+        }
+      }
+    },
+    "WritePolicy": {
+      "type": "object",
+      "properties": {
+        "policy": {
+          "type": "string",
+          "description": "Policy of writes outside of ranges"
+        },
+        "exception": {
+          "type": "integer"
+        },
+        "ranges": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/WritePolicyRange"
+          },
+          "description": "Last added matching range wins"
+        }
+      }
+    },
+    "WriteEvent": {
+      "type": "object",
+      "properties": {
+        "time": {
+          "type": "string"
+        },
+        "server_id": {
+          "type": "integer"
+        },
+        "slave_id": {
+          "type": "integer"
+        },
+        "function_id": {
+          "type": "integer"
+        },
+        "object_type": {
+          "type": "string"
+        },
+        "address": {
+          "type": "integer"
+        },
+        "values": {
+          "type": "array",
+          "items": {
+            "type": "integer"
+          }
+        },
+        "policy": {
+          "type": "string"
+        },
+        "exception": {
+          "type": "integer",
+          "description": "Exception code of rejected or failed write"
+        }
+      }
+    },
+    "ForcedRange": {
+      "type": "object",
+      "properties": {
+        "slave_id": {
+          "type": "integer"
+        },
+        "object_type": {
+          "type": "string"
+        },
+        "start": {
+          "type": "integer"
+        },
+        "end": {
+          "type": "integer"
+        },
+        "values": {
+          "type": "array",
+          "items": {
+            "type": "integer"
+          },
+          "description": "Forced value of every address of range"
+        }
+      }
+    },
+    "Registers": {
+      "type": "object",
+      "properties": {
+        "server_id": {
+          "type": "integer"
+        },
+        "slave_id": {
+          "type": "integer"
+        },
+        "object_type": {
+          "type": "string"
+        },
+        "from": {
+          "type": "integer"
+        },
+        "count": {
+          "type": "integer"
+        },
+        "values": {
+          "type": "array",
+          "items": {
+            "type": "integer"
+          }
+        }
+      }
+    },
+    "RegistersPayload": {
+      "type": "object",
+      "properties": {
+        "values": {
+          "type": "array",
+          "items": {
+            "type": "integer"
+          },
+          "description": "Values of registers (0 or 1 for coils and DI)"
+        }
+      }
     }
   }
 }`
@@ -4,7 +4,10 @@ import (
 	"log"
 	"modbus-emulator/conf"
 	"modbus-emulator/src"
+	registermap "modbus-emulator/src/register_map"
 	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+	trafficrecording "modbus-emulator/src/traffic_recording"
 	"sync"
 
 	"golang.org/x/exp/maps"
@@ -13,17 +16,107 @@ import (
 func main() {
 	log.SetFlags(0)
 	var err error
-	if conf.IsAutoParsingMode {
-		if err = ta.SocketAutoAccumulation(); err != nil {
-			log.Fatalf("Error on sockets auto accumulation: %s", err)
+	if conf.WorkMode == conf.WorkModes.Diff || conf.WorkMode == conf.WorkModes.Analyze || conf.WorkMode == conf.WorkModes.Infer {
+		if conf.IsAutoParsingMode {
+			if err = ta.SocketAutoAccumulation(); err != nil {
+				log.Fatalf("Error on sockets auto accumulation: %s", err)
+			}
 		}
-		src.GenerateConfig()
+		if conf.WorkMode == conf.WorkModes.Diff {
+			if err = ta.CompareDumps(); err != nil {
+				log.Fatalf("Error on comparing dumps: %s", err)
+			}
+		} else if conf.WorkMode == conf.WorkModes.Analyze {
+			if err = ta.AnalyzeDump(); err != nil {
+				log.Fatalf("Error on analyzing dump: %s", err)
+			}
+		} else {
+			if conf.RegisterMapPath == "" {
+				log.Fatal("Error on inferring register map: register map path isn't set")
+			}
+			var history map[string]structs.ServerHistory
+			if history, err = ta.ParseDump(); err != nil {
+				log.Fatalf("Error on parsing dump: %s", err)
+			}
+			var registerMap registermap.RegisterMap
+			if registerMap, err = registermap.Infer(history); err != nil {
+				log.Fatalf("Error on inferring register map: %s", err)
+			}
+			if err = registermap.Save(conf.RegisterMapPath, registerMap); err != nil {
+				log.Fatalf("Error on saving register map: %s", err)
+			}
+			log.Printf("Draft register map with %d tags successfully written to \"%s\"", len(registerMap.Tags), conf.RegisterMapPath)
+		}
+		return
+	}
+	switch conf.DumpFormat {
+	case conf.DumpFormats.SQLite:
+		var history map[string]structs.ServerHistory
+		if history, err = ta.LoadHistoryFromDatabase(); err != nil {
+			log.Fatalf("Error on loading history from database: %s", err)
+		}
+		src.History = structs.HistorySourcesFromMemory(history)
+	case conf.DumpFormats.CSV, conf.DumpFormats.JSON:
+		var history map[string]structs.ServerHistory
+		if history, err = ta.ImportScenario(); err != nil {
+			log.Fatalf("Error on importing scenario: %s", err)
+		}
+		src.History = structs.HistorySourcesFromMemory(history)
+	case conf.DumpFormats.Pcap:
+		if conf.IsAutoParsingMode {
+			if err = ta.SocketAutoAccumulation(); err != nil {
+				log.Fatalf("Error on sockets auto accumulation: %s", err)
+			}
+			src.GenerateConfig()
+		}
+		if conf.HistoryStorePath != "" {
+			if src.History, err = ta.ParseDumpToStore(); err != nil {
+				log.Fatalf("Error on parsing dump to history store: %s", err)
+			}
+		} else {
+			var history map[string]structs.ServerHistory
+			if history, err = ta.ParseDumpWithCache(); err != nil {
+				log.Fatalf("Error on parsing dump: %s", err)
+			}
+			src.History = structs.HistorySourcesFromMemory(history)
+		}
+		if conf.DatabasePath != "" {
+			if err = ta.SaveHistoryToDatabase(src.History); err != nil {
+				log.Fatalf("Error on saving history to database: %s", err)
+			}
+		}
+	default:
+		log.Fatalf("Error: invalid dump format: %s", conf.DumpFormat)
 	}
 	if len(conf.Sockets) == 0 {
 		log.Fatal("Error: empty sockets data")
 	}
-	if src.History, err = ta.ParseDump(); err != nil {
-		log.Fatalf("Error on parsing dump: %s", err)
+	switch conf.WorkMode {
+	case conf.WorkModes.Export:
+		if err = ta.ExportHistory(src.History); err != nil {
+			log.Fatalf("Error on exporting history: %s", err)
+		}
+		return
+	case conf.WorkModes.Emulation:
+	default:
+		log.Fatalf("Error: invalid work mode: %s", conf.WorkMode)
+	}
+	if conf.RegisterMapPath != "" {
+		if src.RegisterMap, err = registermap.Load(conf.RegisterMapPath); err != nil {
+			log.Fatalf("Error on loading register map: %s", err)
+		}
+		log.Printf("Register map with %d tags successfully loaded", len(src.RegisterMap.Tags))
+	}
+	if conf.RecordFilePath != "" {
+		if src.Recorder, err = trafficrecording.NewRecorder(conf.RecordFilePath); err != nil {
+			log.Fatalf("Error on creating traffic recorder: %s", err)
+		}
+		defer src.Recorder.Close()
+	}
+	if conf.IsVerificationEnabled {
+		if src.Verifier, err = ta.NewVerifier(src.History); err != nil {
+			log.Fatalf("Error on creating verifier: %s", err)
+		}
 	}
 	if conf.SimultaneouslyEmulation {
 		src.IsAllEmulatingChannel = make(chan bool, len(conf.Sockets)-1)
@@ -0,0 +1,105 @@
+package src
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"slices"
+	"sync"
+
+	"modbus-emulator/conf"
+
+	mS "github.com/Daniil-Kurganov/modbus-server"
+	reuse "github.com/libp2p/go-reuseport"
+)
+
+type clientRequest struct {
+	mS.Framer
+	clientSocket string
+}
+
+func listenClients(server *mS.Server, servePath string, handlers map[uint8]functionHandler) (listener net.Listener, err error) {
+	if listener, err = reuse.Listen("tcp", servePath); err != nil {
+		return
+	}
+	go acceptClients(server, servePath, listener, handlers)
+	return
+}
+
+func acceptClients(server *mS.Server, servePath string, listener net.Listener, handlers map[uint8]functionHandler) {
+	var requestMutex sync.Mutex
+	isFirstClient := true
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("Error on accepting client of %s: %s", servePath, err)
+			}
+			return
+		}
+		log.Printf("New client of %s: %s", servePath, connection.RemoteAddr())
+		if isFirstClient {
+			if server.ConnectionChanel != nil {
+				server.ConnectionChanel <- &connection
+			}
+			isFirstClient = false
+		}
+		go serveClient(server, servePath, connection, &requestMutex, handlers)
+	}
+}
+
+func serveClient(server *mS.Server, servePath string, connection net.Conn, requestMutex *sync.Mutex, handlers map[uint8]functionHandler) {
+	defer connection.Close()
+	for {
+		packet := make([]byte, 512)
+		bytesRead, err := connection.Read(packet)
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Printf("Error on reading request of %s client: %s", connection.RemoteAddr(), err)
+			}
+			return
+		}
+		var frame mS.Framer
+		if conf.Sockets[servePath].Protocol == conf.Protocols.RTUOverTCP {
+			frame, err = mS.NewRTUFrame(packet[:bytesRead])
+		} else {
+			frame, err = mS.NewTCPFrame(packet[:bytesRead])
+		}
+		if err != nil {
+			log.Printf("Error on parsing request of %s client: %s", connection.RemoteAddr(), err)
+			return
+		}
+		if _, ok := server.Slaves[frame.GetSlaveId()]; !ok || slices.Contains(server.SlavesStoppedResponse, frame.GetSlaveId()) {
+			continue
+		}
+		requestMutex.Lock()
+		response := handleRequest(server, clientRequest{Framer: frame, clientSocket: connection.RemoteAddr().String()}, handlers)
+		requestMutex.Unlock()
+		if _, err = connection.Write(response.Bytes()); err != nil {
+			log.Printf("Error on writing response to %s client: %s", connection.RemoteAddr(), err)
+		}
+	}
+}
+
+func handleRequest(server *mS.Server, request mS.Framer, handlers map[uint8]functionHandler) (response mS.Framer) {
+	response = request.Copy()
+	handler, ok := handlers[request.GetFunction()]
+	if !ok {
+		response.SetException(&mS.IllegalFunction)
+		return
+	}
+	data, exception := handler(server, request)
+	response.SetData(data)
+	if exception != &mS.Success {
+		response.SetException(exception)
+	}
+	return
+}
+
+func clientSocket(request mS.Framer) string {
+	if currentRequest, ok := request.(clientRequest); ok {
+		return currentRequest.clientSocket
+	}
+	return ""
+}
@@ -0,0 +1,246 @@
+package src
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"modbus-emulator/conf"
+	registermap "modbus-emulator/src/register_map"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	mS "github.com/Daniil-Kurganov/modbus-server"
+)
+
+type (
+	emulationCommand struct {
+		action        string
+		steps         int
+		appliedSignal chan bool
+	}
+	breakpoint struct {
+		ID               int    `json:"id"`
+		Kind             string `json:"kind"`
+		TransactionIndex int    `json:"transaction_index"`
+		Timepoint        string `json:"timepoint"`
+		SlaveID          uint8  `json:"slave_id"`
+		FunctionID       uint16 `json:"function_id"`
+		ObjectType       string `json:"object_type"`
+		Address          uint16 `json:"address"`
+		Value            uint16 `json:"value"`
+		timepoint        time.Time
+	}
+	debugState struct {
+		StepsLeft          int          `json:"steps_left"`
+		TransactionIndex   int          `json:"transaction_index"`
+		Breakpoints        []breakpoint `json:"breakpoints"`
+		HitBreakpoint      *breakpoint  `json:"hit_breakpoint,omitempty"`
+		breakpointsCounter int
+	}
+	debugResponse struct {
+		ID          int        `json:"id"`
+		IsEmulating bool       `json:"is_emulating"`
+		Debug       debugState `json:"debug"`
+	}
+)
+
+var emulationActions = struct {
+	Start string
+	Stop  string
+	Step  string
+}{
+	Start: "start",
+	Stop:  "stop",
+	Step:  "step",
+}
+
+func applyEmulationCommand(serverID int, command emulationCommand) {
+	emulationServers.readWriteMutex.Lock()
+	defer emulationServers.readWriteMutex.Unlock()
+	state := &emulationServers.debugStates[serverID]
+	switch command.action {
+	case emulationActions.Start:
+		emulationServers.serversData[serverID].IsEmulating = true
+		state.StepsLeft, state.HitBreakpoint = 0, nil
+		log.Print("Emulation has been started")
+	case emulationActions.Stop:
+		emulationServers.serversData[serverID].IsEmulating = false
+		state.StepsLeft = 0
+		log.Print("Emulation has been stopped")
+	case emulationActions.Step:
+		emulationServers.serversData[serverID].IsEmulating = false
+		state.StepsLeft, state.HitBreakpoint = command.steps, nil
+		log.Printf("Emulation steps %d transactions", command.steps)
+	}
+	if command.appliedSignal != nil {
+		close(command.appliedSignal)
+	}
+}
+
+func isEmulationPaused(serverID int) bool {
+	emulationServers.readWriteMutex.RLock()
+	defer emulationServers.readWriteMutex.RUnlock()
+	return !emulationServers.serversData[serverID].IsEmulating && emulationServers.debugStates[serverID].StepsLeft == 0
+}
+
+func waitEmulationCommand(serverID int, emulationControlChannel chan emulationCommand, rewindChannel chan int) (transactionIndex int, isRewinded bool) {
+	for {
+		select {
+		case command := <-emulationControlChannel:
+			applyEmulationCommand(serverID, command)
+			continue
+		default:
+		}
+		if !isEmulationPaused(serverID) {
+			return
+		}
+		select {
+		case command := <-emulationControlChannel:
+			applyEmulationCommand(serverID, command)
+		case transactionIndex = <-rewindChannel:
+			log.Printf("Rewind (%d)", transactionIndex)
+			isRewinded = true
+		}
+	}
+}
+
+func readBreakpointRegisters(serverID int, server *mS.Server) (registers map[int]uint16) {
+	registers = make(map[int]uint16)
+	emulationServers.registersMutex.RLock()
+	defer emulationServers.registersMutex.RUnlock()
+	emulationServers.readWriteMutex.RLock()
+	defer emulationServers.readWriteMutex.RUnlock()
+	for _, currentBreakpoint := range emulationServers.debugStates[serverID].Breakpoints {
+		if currentBreakpoint.Kind != conf.BreakpointKinds.Register {
+			continue
+		}
+		if currentValue, ok := currentBreakpoint.readRegister(server); ok {
+			registers[currentBreakpoint.ID] = currentValue
+		}
+	}
+	return
+}
+
+func checkBreakpoints(serverID int, server *mS.Server, transactionIndex int, event structs.HistoryEvent, emulationData structs.EmulationData,
+	previousTransactionTime time.Time, registersBefore map[int]uint16) (isPaused bool) {
+	emulationServers.registersMutex.RLock()
+	defer emulationServers.registersMutex.RUnlock()
+	emulationServers.readWriteMutex.Lock()
+	defer emulationServers.readWriteMutex.Unlock()
+	state := &emulationServers.debugStates[serverID]
+	state.TransactionIndex = transactionIndex
+	if state.StepsLeft > 0 {
+		state.StepsLeft--
+	}
+	for _, currentBreakpoint := range state.Breakpoints {
+		if currentBreakpoint.isHit(server, transactionIndex, event, emulationData, previousTransactionTime, registersBefore) {
+			log.Printf("Breakpoint %d (%s) has been hit on transaction %d", currentBreakpoint.ID, currentBreakpoint.Kind, transactionIndex)
+			state.HitBreakpoint = &currentBreakpoint
+			state.StepsLeft = 0
+			emulationServers.serversData[serverID].IsEmulating = false
+			break
+		}
+	}
+	return !emulationServers.serversData[serverID].IsEmulating
+}
+
+func (b *breakpoint) isHit(server *mS.Server, transactionIndex int, event structs.HistoryEvent, emulationData structs.EmulationData,
+	previousTransactionTime time.Time, registersBefore map[int]uint16) bool {
+	switch b.Kind {
+	case conf.BreakpointKinds.TransactionIndex:
+		return transactionIndex == b.TransactionIndex
+	case conf.BreakpointKinds.Timepoint:
+		return !event.TransactionTime.Before(b.timepoint) && previousTransactionTime.Before(b.timepoint)
+	case conf.BreakpointKinds.Function:
+		return (b.SlaveID == 0 || b.SlaveID == event.Header.SlaveID) && (b.FunctionID == 0 || b.FunctionID == emulationData.FunctionID&^0x80)
+	case conf.BreakpointKinds.Register:
+		currentValue, ok := b.readRegister(server)
+		previousValue, wasRead := registersBefore[b.ID]
+		return ok && currentValue == b.Value && (!wasRead || previousValue != b.Value)
+	}
+	return false
+}
+
+func (b *breakpoint) readRegister(server *mS.Server) (value uint16, ok bool) {
+	tag := registermap.Tag{Slave: b.SlaveID, ObjectType: b.ObjectType, Address: b.Address, Type: registermap.Types.Uint16}
+	registers, err := readTagRegisters(server, tag)
+	if err != nil {
+		return
+	}
+	return registers[0], true
+}
+
+func newBreakpoint(kind string, parameters map[string]string) (newBreakpoint breakpoint, err error) {
+	newBreakpoint.Kind = kind
+	parseNumber := func(name string, bitSize int, isRequired bool) (number uint64, err error) {
+		value, ok := parameters[name]
+		if !ok {
+			if isRequired {
+				err = fmt.Errorf("missed required \"%s\" parameter for %s breakpoint", name, kind)
+			}
+			return
+		}
+		if number, err = strconv.ParseUint(value, 10, bitSize); err != nil {
+			err = fmt.Errorf("invalid \"%s\" parameter: %s", name, err)
+		}
+		return
+	}
+	var number uint64
+	switch kind {
+	case conf.BreakpointKinds.TransactionIndex:
+		if number, err = parseNumber("transaction_index", 31, true); err != nil {
+			return
+		}
+		newBreakpoint.TransactionIndex = int(number)
+	case conf.BreakpointKinds.Timepoint:
+		var ok bool
+		if newBreakpoint.Timepoint, ok = parameters["timepoint"]; !ok {
+			err = fmt.Errorf("missed required \"timepoint\" parameter for %s breakpoint", kind)
+			return
+		}
+		if newBreakpoint.timepoint, err = time.ParseInLocation(time.DateTime, newBreakpoint.Timepoint, conf.DumpTimeLocation); err != nil {
+			err = fmt.Errorf("invalid \"timepoint\" parameter: %s", err)
+		}
+	case conf.BreakpointKinds.Function:
+		if number, err = parseNumber("slave_id", 8, false); err != nil {
+			return
+		}
+		newBreakpoint.SlaveID = uint8(number)
+		if number, err = parseNumber("function_id", 8, false); err != nil {
+			return
+		}
+		newBreakpoint.FunctionID = uint16(number)
+		if newBreakpoint.SlaveID == 0 && newBreakpoint.FunctionID == 0 {
+			err = fmt.Errorf("\"slave_id\" or \"function_id\" parameter is required for %s breakpoint", kind)
+		}
+	case conf.BreakpointKinds.Register:
+		if number, err = parseNumber("slave_id", 8, true); err != nil {
+			return
+		}
+		newBreakpoint.SlaveID = uint8(number)
+		var ok bool
+		if newBreakpoint.ObjectType, ok = parameters["object_type"]; !ok {
+			err = fmt.Errorf("missed required \"object_type\" parameter for %s breakpoint", kind)
+			return
+		}
+		if number, err = parseNumber("address", 16, true); err != nil {
+			return
+		}
+		newBreakpoint.Address = uint16(number)
+		if number, err = parseNumber("value", 16, true); err != nil {
+			return
+		}
+		newBreakpoint.Value = uint16(number)
+		tag := registermap.Tag{Name: "breakpoint", ObjectType: newBreakpoint.ObjectType, Address: newBreakpoint.Address, Type: registermap.Types.Uint16}
+		if newBreakpoint.ObjectType == conf.ObjectTypes.Coils || newBreakpoint.ObjectType == conf.ObjectTypes.DI {
+			tag.Type = registermap.Types.Bool
+		}
+		if err = tag.Validate(); err != nil {
+			err = fmt.Errorf("invalid \"object_type\" parameter: %s", newBreakpoint.ObjectType)
+		}
+	default:
+		err = fmt.Errorf("invalid \"kind\" parameter: %s", kind)
+	}
+	return
+}
@@ -3,41 +3,55 @@ package src
 import (
 	"fmt"
 	"log"
+	"net"
 	"reflect"
 	"sync"
 	"time"
 
 	"modbus-emulator/conf"
+	trafficanalysis "modbus-emulator/src/traffic_analysis"
 	"modbus-emulator/src/traffic_analysis/structs"
 
 	mS "github.com/Daniil-Kurganov/modbus-server"
+	"golang.org/x/exp/maps"
 )
 
 var (
-	History               map[string]structs.ServerHistory
+	History               map[string]structs.HistorySource
 	IsAllEmulatingChannel chan (bool)
 )
 
 func ServerInit(waitGroup *sync.WaitGroup, servePath string) {
 	var err error
 	server := mS.NewServer()
-	switch conf.Sockets[servePath].Protocol {
-	case conf.Protocols.RTUOverTCP:
-		if err = server.ListenRTUOverTCP(servePath); err != nil {
-			log.Fatalf("Error on listening RTU over TCP: %s", err)
-		}
-	case conf.Protocols.TCP:
-		if err = server.ListenTCP(servePath); err != nil {
-			log.Fatalf("Error on listening TCP: %s", err)
-		}
-	default:
-		log.Fatalf("Error: invalid servers's work mode: %s", conf.Sockets[servePath].Protocol)
+	playbackRequestChannel := make(chan *playbackRequest)
+	if protocol := conf.Sockets[servePath].Protocol; protocol != conf.Protocols.RTUOverTCP && protocol != conf.Protocols.TCP {
+		log.Fatalf("Error: invalid servers's work mode: %s", protocol)
+	}
+	var listener net.Listener
+	if listener, err = listenClients(server, servePath, newFunctionHandlers(servePath, playbackRequestChannel)); err != nil {
+		log.Fatalf("Error on listening %s: %s", conf.Sockets[servePath].Protocol, err)
 	}
 	log.Printf("Start server on %s, protocol: %s", servePath, conf.Sockets[servePath].Protocol)
 	serverHistory := History[servePath]
-	for _, currentSlaveId := range serverHistory.Slaves {
+	for _, currentSlaveId := range serverHistory.GetSlaves() {
 		server.InitSlave(currentSlaveId)
 	}
+	var keyframes structs.Keyframes
+	if keyframes, err = structs.BuildKeyframes(serverHistory, conf.KeyframeInterval); err != nil {
+		log.Fatalf("Error on reading history of %s: %s", servePath, err)
+	}
+	var startTime, endTime time.Time
+	if startTime, err = serverHistory.GetTransactionTime(0); err != nil {
+		log.Fatalf("Error on reading history of %s: %s", servePath, err)
+	}
+	if endTime, err = serverHistory.GetTransactionTime(serverHistory.Len() - 1); err != nil {
+		log.Fatalf("Error on reading history of %s: %s", servePath, err)
+	}
+	var loop loopSegment
+	if loop, err = newLoopSegment(serverHistory, conf.LoopInTime, conf.LoopOutTime); err != nil {
+		log.Fatalf("Error on setting loop segment of %s: %s", servePath, err)
+	}
 	serverInfo := emulationServerSettings{
 		IsWorking: true,
 		DumpSocketsConfigData: conf.DumpSocketsConfigData{
@@ -46,30 +60,43 @@ func ServerInit(waitGroup *sync.WaitGroup, servePath string) {
 			Protocol:   conf.Sockets[servePath].Protocol,
 		},
 		OneTimeEmulation: conf.OneTimeEmulation,
-		StartTime:        serverHistory.Transactions[0].TransactionTime.String(),
-		EndTime:          serverHistory.Transactions[len(serverHistory.Transactions)-1].TransactionTime.String(),
+		Speed:            conf.PlaybackSpeed,
+		Loop:             loop,
+		ResetPolicy:      conf.ResetPolicy,
+		WritePolicy:      structs.WritePolicySettings{Policy: conf.WritePolicy, Exception: conf.WriteRejectException},
+		StartTime:        startTime.String(),
+		EndTime:          endTime.String(),
 		CurrentTime:      "",
 	}
 	rewindChannel := make(chan int)
-	emulationControlChannel := make(chan bool)
+	emulationControlChannel := make(chan emulationCommand)
+	speedChannel := make(chan bool, 1)
 	emulationServers.readWriteMutex.Lock()
 	emulationServers.serversData = append(emulationServers.serversData, serverInfo)
 	emulationServers.servers = append(emulationServers.servers, server)
 	emulationServers.rewindChannels = append(emulationServers.rewindChannels, rewindChannel)
 	emulationServers.emulationControlChannels = append(emulationServers.emulationControlChannels, emulationControlChannel)
+	emulationServers.speedChannels = append(emulationServers.speedChannels, speedChannel)
+	emulationServers.debugStates = append(emulationServers.debugStates, debugState{})
+	emulationServers.clientWrites = append(emulationServers.clientWrites, make(structs.RegistersImage))
+	emulationServers.writeEvents = append(emulationServers.writeEvents, nil)
 	emulationServers.readWriteMutex.Unlock()
 	emulationServers.readWriteMutex.RLock()
 	serverID := len(emulationServers.serversData) - 1
 	emulationServers.readWriteMutex.RUnlock()
+	if conf.IsPrefillEnabled {
+		prefillRegisters(server, serverHistory, &keyframes, 0, nil)
+	}
 	closeChannel := make(chan bool)
-	go emulate(server, serverHistory.Transactions, closeChannel, serverID, rewindChannel, emulationControlChannel)
+	go emulate(server, servePath, serverHistory, &keyframes, closeChannel, serverID, rewindChannel, emulationControlChannel, speedChannel, playbackRequestChannel)
 	<-closeChannel
 	close(closeChannel)
+	listener.Close()
 	server.Close()
 	waitGroup.Done()
 }
 
-func emulate(server *mS.Server, history []structs.HistoryEvent, closeChannel chan (bool), serverID int, rewindChannel chan int, emulationControlChannel chan bool) {
+func emulate(server *mS.Server, servePath string, history structs.HistorySource, keyframes *structs.Keyframes, closeChannel chan (bool), serverID int, rewindChannel chan int, emulationControlChannel chan emulationCommand, speedChannel chan bool, playbackRequestChannel chan *playbackRequest) {
 	if conf.SimultaneouslyEmulation {
 		select {
 		case <-server.ConnectionChanel:
@@ -86,167 +113,326 @@ func emulate(server *mS.Server, history []structs.HistoryEvent, closeChannel cha
 	emulationServers.readWriteMutex.Lock()
 	emulationServers.serversData[serverID].IsEmulating = true
 	emulationServers.readWriteMutex.Unlock()
+	var previousTransactionTime time.Time
+	isRestarted := false
 	for {
-		for currentIndex := 0; currentIndex < len(history); currentIndex++ {
-			select {
-			case <-emulationControlChannel:
-				log.Print("Emulation has been stopped")
-				emulationServers.readWriteMutex.Lock()
-				emulationServers.serversData[serverID].IsEmulating = false
-				emulationServers.readWriteMutex.Unlock()
-				<-emulationControlChannel
-				log.Print("Emulation has been started")
-				emulationServers.readWriteMutex.Lock()
-				emulationServers.serversData[serverID].IsEmulating = true
-				emulationServers.readWriteMutex.Unlock()
-			default:
+		for currentIndex := loopStartIndex(server, serverID, history, keyframes, isRestarted); currentIndex < history.Len(); currentIndex = nextTransactionIndex(server, serverID, history, keyframes, currentIndex) {
+			if transactionIndex, isRewinded := waitEmulationCommand(serverID, emulationControlChannel, rewindChannel); isRewinded {
+				currentIndex, previousTransactionTime = transactionIndex, time.Time{}
+				restoreRegistersImage(server, history, keyframes, currentIndex)
 			}
 			var currentHistoryEvent structs.HistoryEvent
+			var err error
 			select {
 			case transactionIndex := <-rewindChannel:
-				log.Printf("Rewind (%d):\n %v", transactionIndex, history[transactionIndex])
-				currentIndex = transactionIndex
+				log.Printf("Rewind (%d)", transactionIndex)
+				currentIndex, previousTransactionTime = transactionIndex, time.Time{}
+				restoreRegistersImage(server, history, keyframes, currentIndex)
 			default:
 			}
-			currentHistoryEvent = history[currentIndex]
+			var currentRequest *playbackRequest
+			if conf.PlaybackMode == conf.PlaybackModes.Request {
+				var isRewinded bool
+				if currentRequest, currentIndex, isRewinded = waitPlaybackRequest(server, serverID, history, keyframes, currentIndex, playbackRequestChannel, emulationControlChannel, rewindChannel); isRewinded {
+					previousTransactionTime = time.Time{}
+				}
+			}
+			if currentHistoryEvent, err = history.GetEvent(currentIndex); err != nil {
+				log.Printf("Error on reading history: %s", err)
+				releasePlaybackRequest(currentRequest)
+				continue
+			}
 			emulationServers.readWriteMutex.Lock()
 			emulationServers.serversData[serverID].CurrentTime = currentHistoryEvent.TransactionTime.String()
 			emulationServers.readWriteMutex.Unlock()
 			var timeEmulation time.Duration
-			if currentIndex == len(history)-1 {
+			if currentIndex == history.Len()-1 {
 				timeEmulation = conf.FinishDelayTime
 			} else {
-				timeEmulation = history[currentIndex+1].TransactionTime.Sub(currentHistoryEvent.TransactionTime)
+				var nextTransactionTime time.Time
+				if nextTransactionTime, err = history.GetTransactionTime(currentIndex + 1); err != nil {
+					log.Printf("Error on reading history: %s", err)
+					releasePlaybackRequest(currentRequest)
+					continue
+				}
+				timeEmulation = nextTransactionTime.Sub(currentHistoryEvent.TransactionTime)
+				if conf.MaxGapTime > 0 && timeEmulation > conf.MaxGapTime {
+					if conf.IsGapCompressionLogged {
+						log.Printf("Idle gap of %v after %s compressed to %v", timeEmulation, currentHistoryEvent.TransactionTime, conf.MaxGapTime)
+					}
+					timeEmulation = conf.MaxGapTime
+				}
 			}
 			currentHistoryEvent.LogPrint()
-			var currentObjectType, currentOperation string
+			breakpointRegisters := readBreakpointRegisters(serverID, server)
+			var currentEmulationData structs.EmulationData
+			isApplied := false
 			if currentHistoryEvent.Handshake.TransactionErrorCheck() {
 				log.Print("Current transaction isn't valid")
-				continue
-			}
-			var currentEmulationData structs.EmulationData
-			var err error
-			if currentEmulationData, err = currentHistoryEvent.Handshake.Marshal(); err != nil {
+			} else if currentEmulationData, err = currentHistoryEvent.Handshake.Marshal(); err != nil {
 				log.Printf("Error: %s", err)
+			} else {
+				isApplied = true
+				emulationServers.registersMutex.Lock()
+				currentObjectType, currentOperation := applyEmulationData(server, currentHistoryEvent.Header.SlaveID, pinEmulationData(server, currentHistoryEvent.Header.SlaveID, currentEmulationData))
+				currentRightBorder := int(currentEmulationData.Address + currentEmulationData.Quantity)
+				logTagValues(server, servePath, currentHistoryEvent.Header.SlaveID, currentObjectType, int(currentEmulationData.Address), max(currentRightBorder, int(currentEmulationData.Address)+1))
+				emulationServers.registersMutex.Unlock()
+				log.Printf("\nCurrent iteration:\n slave ID: %d\n object type: %s\n operation: %s\n delay: %v\n\n",
+					currentHistoryEvent.Header.SlaveID,
+					currentObjectType,
+					currentOperation,
+					timeEmulation)
+			}
+			releasePlaybackRequest(currentRequest)
+			isPaused := checkBreakpoints(serverID, server, currentIndex, currentHistoryEvent, currentEmulationData, previousTransactionTime, breakpointRegisters)
+			previousTransactionTime = currentHistoryEvent.TransactionTime
+			if isPaused || !isApplied || currentRequest != nil {
 				continue
 			}
-			currentRightBorder := int(currentEmulationData.Address + currentEmulationData.Quantity)
-			switch currentEmulationData.FunctionID {
-			case conf.Functions.CoilsRead:
-				currentObjectType, currentOperation = "coils", "read"
-				log.Printf("\n\n Before: Coils[%d:%d] = %d",
-					currentEmulationData.Address, currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentEmulationData.Address:currentRightBorder])
-				if !reflect.DeepEqual(server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentEmulationData.Address:currentRightBorder], sliceUint16ToByte(currentEmulationData.Payload)) {
-					for currentIndex := int(currentEmulationData.Address); currentIndex < currentRightBorder; currentIndex++ {
-						server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentIndex] = byte(currentEmulationData.Payload[currentIndex-int(currentEmulationData.Address)])
-					}
-				}
-				log.Printf(" After: Coils[%d:%d] = %d",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentEmulationData.Address:currentRightBorder])
-			case conf.Functions.DIRead:
-				currentObjectType, currentOperation = "DI", "read"
-				log.Printf("\n\n Before: DI[%d:%d] = %d",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].DiscreteInputs[currentEmulationData.Address:currentRightBorder])
-				if !reflect.DeepEqual(server.Slaves[currentHistoryEvent.Header.SlaveID].DiscreteInputs[currentEmulationData.Address:currentRightBorder], sliceUint16ToByte(currentEmulationData.Payload)) {
-					for currentIndex := int(currentEmulationData.Address); currentIndex < currentRightBorder; currentIndex++ {
-						server.Slaves[currentHistoryEvent.Header.SlaveID].DiscreteInputs[currentIndex] = byte(currentEmulationData.Payload[currentIndex-int(currentEmulationData.Address)])
-					}
-				}
-				log.Printf(" After: DI[%d:%d] = %d",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].DiscreteInputs[currentEmulationData.Address:currentRightBorder])
-			case conf.Functions.HRRead:
-				currentObjectType, currentOperation = "HR", "read"
-				log.Printf("\n\n Before: HR[%d:%d] = %d",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentEmulationData.Address:currentRightBorder])
-				if !reflect.DeepEqual(server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentEmulationData.Address:currentRightBorder], sliceUint16ToByte(currentEmulationData.Payload)) {
-					for currentIndex := int(currentEmulationData.Address); currentIndex < currentRightBorder; currentIndex++ {
-						server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentIndex] = currentEmulationData.Payload[currentIndex-int(currentEmulationData.Address)]
-					}
-				}
-				log.Printf(" After: HR[%d:%d] = %d",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentEmulationData.Address:currentRightBorder])
-			case conf.Functions.IRRead:
-				currentObjectType, currentOperation = "IR", "read"
-				log.Printf("\n\n Before: IR[%d:%d] = %d", currentEmulationData.Address, currentRightBorder, server.Slaves[currentHistoryEvent.Header.SlaveID].InputRegisters[currentEmulationData.Address:currentRightBorder])
-				if !reflect.DeepEqual(server.Slaves[currentHistoryEvent.Header.SlaveID].InputRegisters[currentEmulationData.Address:currentRightBorder], sliceUint16ToByte(currentEmulationData.Payload)) {
-					for currentIndex := int(currentEmulationData.Address); currentIndex < currentRightBorder; currentIndex++ {
-						server.Slaves[currentHistoryEvent.Header.SlaveID].InputRegisters[currentIndex] = currentEmulationData.Payload[currentIndex-int(currentEmulationData.Address)]
-					}
-				}
-				log.Printf(" After: IR[%d:%d] = %d",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].InputRegisters[currentEmulationData.Address:currentRightBorder])
-			case conf.Functions.CoilsSimpleWrite:
-				currentObjectType, currentOperation = "coils", "simple write"
-				log.Printf("\n\n Before: Coils[%d] = %d",
-					currentEmulationData.Address,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentEmulationData.Address])
-				server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentEmulationData.Address] = byte(currentEmulationData.Payload[0])
-				log.Printf(" After: Coils[%d] = %d", currentEmulationData.Address, server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentEmulationData.Address])
-			case conf.Functions.HRSimpleWrite:
-				currentObjectType, currentOperation = "HR", "simple write"
-				log.Printf("\n\n Before: HR[%d] = %d", currentEmulationData.Address, server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentEmulationData.Address])
-				server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentEmulationData.Address] = currentEmulationData.Payload[0]
-				log.Printf(" After: HR[%d] = %d", currentEmulationData.Address, server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentEmulationData.Address])
-			case conf.Functions.CoilsMultipleWrite:
-				currentObjectType, currentOperation = "coils", "multiple write"
-				log.Printf("\n\n Before: Coils[%d:%d] = %v",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentEmulationData.Address:currentRightBorder])
-				for currentIndex := int(currentEmulationData.Address); currentIndex < int(currentRightBorder); currentIndex++ {
-					server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentIndex] = byte(currentEmulationData.Payload[currentIndex-int(currentEmulationData.Address)])
-				}
-				log.Printf(" After: Coils[%d:%d] = %v",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].Coils[currentEmulationData.Address:currentRightBorder])
-			case conf.Functions.HRMultipleWrite:
-				currentObjectType, currentOperation = "HR", "multiple write"
-				log.Printf("\n\n Before: HR[%d:%d] = %v",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentEmulationData.Address:currentRightBorder])
-				for currentIndex := int(currentEmulationData.Address); currentIndex < int(currentRightBorder); currentIndex++ {
-					server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentIndex] = currentEmulationData.Payload[currentIndex-int(currentEmulationData.Address)]
-				}
-				log.Printf(" After: HR[%d:%d] = %v",
-					currentEmulationData.Address,
-					currentRightBorder,
-					server.Slaves[currentHistoryEvent.Header.SlaveID].HoldingRegisters[currentEmulationData.Address:currentRightBorder])
+			if command, isInterrupted := waitEmulation(serverID, timeEmulation, speedChannel, emulationControlChannel); isInterrupted {
+				applyEmulationCommand(serverID, command)
 			}
-			log.Printf("\nCurrent iteration:\n slave ID: %d\n object type: %s\n operation: %s\n delay: %v\n\n",
-				currentHistoryEvent.Header.SlaveID,
-				currentObjectType,
-				currentOperation,
-				timeEmulation)
-			time.Sleep(timeEmulation)
 		}
 		log.Print("\nEnd of dump history file.")
-		emulationServers.readWriteMutex.Lock()
-		defer emulationServers.readWriteMutex.Unlock()
-		if emulationServers.serversData[serverID].OneTimeEmulation {
+		if finishEmulationPass(serverID) {
 			log.Print("Emulation mode: one-time. Closing connection")
-			emulationServers.serversData[serverID].IsWorking = false
+			finishVerification(servePath)
 			closeChannel <- true
 			return
 		}
+		previousTransactionTime, isRestarted = time.Time{}, true
 		log.Print("Emulation mode: continuously. Starting new loop of emulation")
 	}
 }
 
+func finishEmulationPass(serverID int) (isFinished bool) {
+	emulationServers.readWriteMutex.Lock()
+	defer emulationServers.readWriteMutex.Unlock()
+	if isFinished = emulationServers.serversData[serverID].OneTimeEmulation; isFinished {
+		emulationServers.serversData[serverID].IsWorking = false
+	}
+	return
+}
+
+func applyEmulationData(server *mS.Server, slaveID uint8, emulationData structs.EmulationData) (objectType, operation string) {
+	rightBorder := int(emulationData.Address + emulationData.Quantity)
+	switch emulationData.FunctionID {
+	case conf.Functions.CoilsRead:
+		objectType, operation = "coils", "read"
+		log.Printf("\n\n Before: Coils[%d:%d] = %d",
+			emulationData.Address, rightBorder,
+			server.Slaves[slaveID].Coils[emulationData.Address:rightBorder])
+		if !reflect.DeepEqual(server.Slaves[slaveID].Coils[emulationData.Address:rightBorder], sliceUint16ToByte(emulationData.Payload)) {
+			for currentIndex := int(emulationData.Address); currentIndex < rightBorder; currentIndex++ {
+				server.Slaves[slaveID].Coils[currentIndex] = byte(emulationData.Payload[currentIndex-int(emulationData.Address)])
+			}
+		}
+		log.Printf(" After: Coils[%d:%d] = %d",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].Coils[emulationData.Address:rightBorder])
+	case conf.Functions.DIRead:
+		objectType, operation = "DI", "read"
+		log.Printf("\n\n Before: DI[%d:%d] = %d",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].DiscreteInputs[emulationData.Address:rightBorder])
+		if !reflect.DeepEqual(server.Slaves[slaveID].DiscreteInputs[emulationData.Address:rightBorder], sliceUint16ToByte(emulationData.Payload)) {
+			for currentIndex := int(emulationData.Address); currentIndex < rightBorder; currentIndex++ {
+				server.Slaves[slaveID].DiscreteInputs[currentIndex] = byte(emulationData.Payload[currentIndex-int(emulationData.Address)])
+			}
+		}
+		log.Printf(" After: DI[%d:%d] = %d",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].DiscreteInputs[emulationData.Address:rightBorder])
+	case conf.Functions.HRRead:
+		objectType, operation = "HR", "read"
+		log.Printf("\n\n Before: HR[%d:%d] = %d",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].HoldingRegisters[emulationData.Address:rightBorder])
+		if !reflect.DeepEqual(server.Slaves[slaveID].HoldingRegisters[emulationData.Address:rightBorder], sliceUint16ToByte(emulationData.Payload)) {
+			for currentIndex := int(emulationData.Address); currentIndex < rightBorder; currentIndex++ {
+				server.Slaves[slaveID].HoldingRegisters[currentIndex] = emulationData.Payload[currentIndex-int(emulationData.Address)]
+			}
+		}
+		log.Printf(" After: HR[%d:%d] = %d",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].HoldingRegisters[emulationData.Address:rightBorder])
+	case conf.Functions.IRRead:
+		objectType, operation = "IR", "read"
+		log.Printf("\n\n Before: IR[%d:%d] = %d", emulationData.Address, rightBorder, server.Slaves[slaveID].InputRegisters[emulationData.Address:rightBorder])
+		if !reflect.DeepEqual(server.Slaves[slaveID].InputRegisters[emulationData.Address:rightBorder], sliceUint16ToByte(emulationData.Payload)) {
+			for currentIndex := int(emulationData.Address); currentIndex < rightBorder; currentIndex++ {
+				server.Slaves[slaveID].InputRegisters[currentIndex] = emulationData.Payload[currentIndex-int(emulationData.Address)]
+			}
+		}
+		log.Printf(" After: IR[%d:%d] = %d",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].InputRegisters[emulationData.Address:rightBorder])
+	case conf.Functions.CoilsSimpleWrite:
+		objectType, operation = "coils", "simple write"
+		log.Printf("\n\n Before: Coils[%d] = %d",
+			emulationData.Address,
+			server.Slaves[slaveID].Coils[emulationData.Address])
+		server.Slaves[slaveID].Coils[emulationData.Address] = byte(emulationData.Payload[0])
+		log.Printf(" After: Coils[%d] = %d", emulationData.Address, server.Slaves[slaveID].Coils[emulationData.Address])
+	case conf.Functions.HRSimpleWrite:
+		objectType, operation = "HR", "simple write"
+		log.Printf("\n\n Before: HR[%d] = %d", emulationData.Address, server.Slaves[slaveID].HoldingRegisters[emulationData.Address])
+		server.Slaves[slaveID].HoldingRegisters[emulationData.Address] = emulationData.Payload[0]
+		log.Printf(" After: HR[%d] = %d", emulationData.Address, server.Slaves[slaveID].HoldingRegisters[emulationData.Address])
+	case conf.Functions.CoilsMultipleWrite:
+		objectType, operation = "coils", "multiple write"
+		log.Printf("\n\n Before: Coils[%d:%d] = %v",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].Coils[emulationData.Address:rightBorder])
+		for currentIndex := int(emulationData.Address); currentIndex < int(rightBorder); currentIndex++ {
+			server.Slaves[slaveID].Coils[currentIndex] = byte(emulationData.Payload[currentIndex-int(emulationData.Address)])
+		}
+		log.Printf(" After: Coils[%d:%d] = %v",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].Coils[emulationData.Address:rightBorder])
+	case conf.Functions.HRMultipleWrite:
+		objectType, operation = "HR", "multiple write"
+		log.Printf("\n\n Before: HR[%d:%d] = %v",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].HoldingRegisters[emulationData.Address:rightBorder])
+		for currentIndex := int(emulationData.Address); currentIndex < int(rightBorder); currentIndex++ {
+			server.Slaves[slaveID].HoldingRegisters[currentIndex] = emulationData.Payload[currentIndex-int(emulationData.Address)]
+		}
+		log.Printf(" After: HR[%d:%d] = %v",
+			emulationData.Address,
+			rightBorder,
+			server.Slaves[slaveID].HoldingRegisters[emulationData.Address:rightBorder])
+	}
+	return
+}
+
+func finishVerification(servePath string) {
+	if Verifier == nil || !Verifier.Finish(servePath) {
+		return
+	}
+	report := Verifier.Report()
+	log.Printf("Verification has been finished: passed = %t", report.Passed)
+	if err := trafficanalysis.WriteVerificationReport(report); err != nil {
+		log.Printf("Error on writing verification report: %s", err)
+	}
+}
+
+func restoreRegistersImage(server *mS.Server, history structs.HistorySource, keyframes *structs.Keyframes, transactionIndex int) {
+	image, err := keyframes.Image(history, transactionIndex)
+	if err != nil {
+		log.Printf("Error on restoring registers image: %s", err)
+		return
+	}
+	writeRegistersImage(server, image, keyframes.Addresses)
+	log.Printf("Registers image has been restored to transaction %d", transactionIndex)
+	if conf.IsPrefillEnabled {
+		prefillRegisters(server, history, keyframes, transactionIndex, image)
+	}
+}
+
+func prefillRegisters(server *mS.Server, history structs.HistorySource, keyframes *structs.Keyframes, transactionIndex int, knownImage structs.RegistersImage) {
+	image, err := keyframes.Lookahead(history, transactionIndex, knownImage)
+	if err != nil {
+		log.Printf("Error on prefilling registers: %s", err)
+		return
+	}
+	writeRegistersImage(server, image, maps.Keys(image))
+	log.Printf("%d registers have been prefilled with first values from transaction %d", len(image), transactionIndex)
+}
+
+func writeRegistersImage(server *mS.Server, image structs.RegistersImage, addresses []structs.RegisterAddress) {
+	emulationServers.registersMutex.Lock()
+	defer emulationServers.registersMutex.Unlock()
+	for _, currentAddress := range addresses {
+		setRegister(server, currentAddress, image[currentAddress])
+	}
+	pinRegisters(server)
+}
+
+func setRegister(server *mS.Server, address structs.RegisterAddress, value uint16) {
+	slave, ok := server.Slaves[address.SlaveID]
+	if !ok {
+		return
+	}
+	switch address.ObjectType {
+	case conf.ObjectTypes.Coils:
+		slave.Coils[address.Address] = byte(value)
+	case conf.ObjectTypes.DI:
+		slave.DiscreteInputs[address.Address] = byte(value)
+	case conf.ObjectTypes.HR:
+		slave.HoldingRegisters[address.Address] = value
+	case conf.ObjectTypes.IR:
+		slave.InputRegisters[address.Address] = value
+	}
+}
+
+func waitPlaybackRequest(server *mS.Server, serverID int, history structs.HistorySource, keyframes *structs.Keyframes, transactionIndex int,
+	playbackRequestChannel chan *playbackRequest, emulationControlChannel chan emulationCommand, rewindChannel chan int) (request *playbackRequest, currentIndex int, isRewinded bool) {
+	currentIndex = transactionIndex
+	for {
+		var key structs.RequestKey
+		currentEvent, err := history.GetEvent(currentIndex)
+		if err == nil {
+			key, err = currentEvent.RequestKey()
+		}
+		if err != nil {
+			log.Printf("Error on reading expected request: %s", err)
+			return
+		}
+		select {
+		case command := <-emulationControlChannel:
+			applyEmulationCommand(serverID, command)
+		case currentIndex = <-rewindChannel:
+			log.Printf("Rewind (%d)", currentIndex)
+			restoreRegistersImage(server, history, keyframes, currentIndex)
+			isRewinded = true
+		case request = <-playbackRequestChannel:
+			if !isEmulationPaused(serverID) && request.key == key {
+				return
+			}
+			releasePlaybackRequest(request)
+			request = nil
+		}
+	}
+}
+
+func releasePlaybackRequest(request *playbackRequest) {
+	if request != nil {
+		close(request.appliedSignal)
+	}
+}
+
+func waitEmulation(serverID int, timeEmulation time.Duration, speedChannel chan bool, emulationControlChannel chan emulationCommand) (command emulationCommand, isInterrupted bool) {
+	for timeEmulation > 0 {
+		emulationServers.readWriteMutex.RLock()
+		speed := emulationServers.serversData[serverID].Speed
+		emulationServers.readWriteMutex.RUnlock()
+		if speed == conf.AsFastAsPossibleFactor {
+			return
+		}
+		startTime := time.Now()
+		select {
+		case <-time.After(time.Duration(float64(timeEmulation) / speed)):
+			return
+		case <-speedChannel:
+			timeEmulation -= time.Duration(float64(time.Since(startTime)) * speed)
+		case command = <-emulationControlChannel:
+			return command, true
+		}
+	}
+	return
+}
+
 func sliceUint16ToByte(source []uint16) (destination []byte) {
 	for _, currentByte := range source {
 		destination = append(destination, byte(currentByte))
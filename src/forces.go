@@ -0,0 +1,13 @@
+package src
+
+import (
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	mS "github.com/Daniil-Kurganov/modbus-server"
+)
+
+func applyForce(server *mS.Server, force structs.ForcedRange) {
+	for currentAddress := int(force.Start); currentAddress <= int(force.End); currentAddress++ {
+		setRegister(server, structs.RegisterAddress{SlaveID: force.SlaveID, ObjectType: force.ObjectType, Address: uint16(currentAddress)}, force.Values[currentAddress-int(force.Start)])
+	}
+}
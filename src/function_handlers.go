@@ -0,0 +1,121 @@
+package src
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"modbus-emulator/conf"
+	trafficanalysis "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+	trafficrecording "modbus-emulator/src/traffic_recording"
+
+	mS "github.com/Daniil-Kurganov/modbus-server"
+)
+
+type (
+	functionHandler func(*mS.Server, mS.Framer) ([]byte, *mS.Exception)
+	playbackRequest struct {
+		key           structs.RequestKey
+		appliedSignal chan bool
+	}
+)
+
+var (
+	Recorder                *trafficrecording.Recorder
+	Verifier                *trafficanalysis.Verifier
+	defaultFunctionHandlers = map[uint16]functionHandler{
+		conf.Functions.CoilsRead:          mS.ReadCoils,
+		conf.Functions.DIRead:             mS.ReadDiscreteInputs,
+		conf.Functions.HRRead:             mS.ReadHoldingRegisters,
+		conf.Functions.IRRead:             mS.ReadInputRegisters,
+		conf.Functions.CoilsSimpleWrite:   mS.WriteSingleCoil,
+		conf.Functions.HRSimpleWrite:      mS.WriteHoldingRegister,
+		conf.Functions.CoilsMultipleWrite: mS.WriteMultipleCoils,
+		conf.Functions.HRMultipleWrite:    mS.WriteHoldingRegisters,
+	}
+)
+
+func newFunctionHandlers(servePath string, playbackRequestChannel chan *playbackRequest) (handlers map[uint8]functionHandler) {
+	handlers = make(map[uint8]functionHandler)
+	for currentFunctionID, currentHandler := range defaultFunctionHandlers {
+		if slices.Contains(writeFunctions, currentFunctionID) {
+			currentHandler = writePolicyFunctionHandler(currentHandler)
+		}
+		currentHandler = lockingFunctionHandler(slices.Contains(writeFunctions, currentFunctionID), currentHandler)
+		if conf.PlaybackMode == conf.PlaybackModes.Request {
+			currentHandler = requestPlaybackFunctionHandler(playbackRequestChannel, currentHandler)
+		}
+		if Verifier != nil {
+			currentHandler = verificationFunctionHandler(servePath, currentHandler)
+		}
+		if Recorder != nil {
+			currentHandler = recordingFunctionHandler(servePath, currentHandler)
+		}
+		handlers[uint8(currentFunctionID)] = currentHandler
+	}
+	return
+}
+
+func lockingFunctionHandler(isWrite bool, handler functionHandler) functionHandler {
+	return func(server *mS.Server, request mS.Framer) ([]byte, *mS.Exception) {
+		if isWrite {
+			emulationServers.registersMutex.Lock()
+			defer emulationServers.registersMutex.Unlock()
+		} else {
+			emulationServers.registersMutex.RLock()
+			defer emulationServers.registersMutex.RUnlock()
+		}
+		return handler(server, request)
+	}
+}
+
+func recordingFunctionHandler(servePath string, handler functionHandler) functionHandler {
+	serverSocket := fmt.Sprintf("%s:%s", conf.Sockets[servePath].HostAddress, conf.Sockets[servePath].PortAddress)
+	return func(server *mS.Server, request mS.Framer) (data []byte, exception *mS.Exception) {
+		requestTime := time.Now()
+		data, exception = handler(server, request)
+		response := request.Copy()
+		response.SetData(data)
+		if exception != &mS.Success {
+			response.SetException(exception)
+		}
+		if err := Recorder.WriteTransaction(clientSocket(request), serverSocket, requestTime, time.Now(), request.Bytes(), response.Bytes()); err != nil {
+			log.Printf("Error on recording transaction of %s: %s", servePath, err)
+		}
+		return
+	}
+}
+
+func verificationFunctionHandler(servePath string, handler functionHandler) functionHandler {
+	return func(server *mS.Server, request mS.Framer) ([]byte, *mS.Exception) {
+		key, err := structs.NewRequestKey(request.GetSlaveId(), uint16(request.GetFunction()), request.GetData())
+		if err != nil {
+			log.Printf("Error on verifying request of %s: %s", servePath, err)
+			return handler(server, request)
+		}
+		verifiedRequest := trafficanalysis.VerifiedRequest{RequestKey: key, Values: structs.NewRequestValues(key.FunctionID, request.GetData())}
+		if deviation := Verifier.Check(servePath, verifiedRequest, time.Now()); deviation != nil {
+			log.Printf("Verification deviation on %s: %s", servePath, deviation)
+		}
+		return handler(server, request)
+	}
+}
+
+func requestPlaybackFunctionHandler(playbackRequestChannel chan *playbackRequest, handler functionHandler) functionHandler {
+	return func(server *mS.Server, request mS.Framer) ([]byte, *mS.Exception) {
+		key, err := structs.NewRequestKey(request.GetSlaveId(), uint16(request.GetFunction()), request.GetData())
+		if err != nil {
+			log.Printf("Error on matching request: %s", err)
+			return handler(server, request)
+		}
+		currentRequest := &playbackRequest{key: key, appliedSignal: make(chan bool)}
+		select {
+		case playbackRequestChannel <- currentRequest:
+			<-currentRequest.appliedSignal
+		case <-time.After(conf.PlaybackRequestTimeout):
+		}
+		return handler(server, request)
+	}
+}
@@ -22,6 +22,30 @@ func GenerateConfig() (err error) {
 	newConfig, _ = tW.WriteValue(conf.OneTimeEmulation, newConfig, nil, conf.GenFileTitles.OneTimeEmulation, nil)
 	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.DumpTimeLocation), newConfig, nil, conf.GenFileTitles.DumpTimeLocation, nil)
 	newConfig, _ = tW.WriteValue(conf.SimultaneouslyEmulation, newConfig, nil, conf.GenFileTitles.SimultaneouslyEmulation, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.ParsedHistoryCachePath), newConfig, nil, conf.GenFileTitles.ParsedHistoryCachePath, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.HistoryStorePath), newConfig, nil, conf.GenFileTitles.HistoryStorePath, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.DumpFormat), newConfig, nil, conf.GenFileTitles.DumpFormat, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.DatabasePath), newConfig, nil, conf.GenFileTitles.DatabasePath, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.WorkMode), newConfig, nil, conf.GenFileTitles.WorkMode, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.ExportFormat), newConfig, nil, conf.GenFileTitles.ExportFormat, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.ExportFilePath), newConfig, nil, conf.GenFileTitles.ExportFilePath, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.RecordFilePath), newConfig, nil, conf.GenFileTitles.RecordFilePath, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.CompareDumpFilePath), newConfig, nil, conf.GenFileTitles.CompareDumpFilePath, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.ReportFormat), newConfig, nil, conf.GenFileTitles.ReportFormat, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.ReportFilePath), newConfig, nil, conf.GenFileTitles.ReportFilePath, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.RegisterMapPath), newConfig, nil, conf.GenFileTitles.RegisterMapPath, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.FormatPlaybackSpeed(conf.PlaybackSpeed)), newConfig, nil, conf.GenFileTitles.PlaybackSpeed, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.MaxGapTime), newConfig, nil, conf.GenFileTitles.MaxGapTime, nil)
+	newConfig, _ = tW.WriteValue(conf.IsGapCompressionLogged, newConfig, nil, conf.GenFileTitles.IsGapCompressionLogged, nil)
+	newConfig, _ = tW.WriteValue(conf.KeyframeInterval, newConfig, nil, conf.GenFileTitles.KeyframeInterval, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.LoopInTime), newConfig, nil, conf.GenFileTitles.LoopInTime, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("'%s'", conf.LoopOutTime), newConfig, nil, conf.GenFileTitles.LoopOutTime, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.ResetPolicy), newConfig, nil, conf.GenFileTitles.ResetPolicy, nil)
+	newConfig, _ = tW.WriteValue(conf.IsPrefillEnabled, newConfig, nil, conf.GenFileTitles.IsPrefillEnabled, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.PlaybackMode), newConfig, nil, conf.GenFileTitles.PlaybackMode, nil)
+	newConfig, _ = tW.WriteValue(conf.IsVerificationEnabled, newConfig, nil, conf.GenFileTitles.IsVerificationEnabled, nil)
+	newConfig, _ = tW.WriteValue(fmt.Sprintf("\"%s\"", conf.WritePolicy), newConfig, nil, conf.GenFileTitles.WritePolicy, nil)
+	newConfig, _ = tW.WriteValue(conf.WriteRejectException, newConfig, nil, conf.GenFileTitles.WriteRejectException, nil)
 	for currentEmulateSocket, currentDumpSocketData := range conf.Sockets {
 		var currentDumpSocket, currentRealSocket string
 		if currentDumpSocketData.PortAddress == conf.ServerDefaultDumpPort {
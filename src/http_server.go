@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"modbus-emulator/conf"
+	registermap "modbus-emulator/src/register_map"
+	"modbus-emulator/src/traffic_analysis/structs"
 	"net"
 	"net/http"
 	"slices"
@@ -27,10 +29,15 @@ type (
 		IsWorking   bool `json:"is_working"`
 		IsEmulating bool `json:"is_emulating"`
 		conf.DumpSocketsConfigData
-		OneTimeEmulation bool   `json:"one_time_emulation"`
-		StartTime        string `json:"start_time"`
-		EndTime          string `json:"end_time"`
-		CurrentTime      string `json:"current_time"`
+		OneTimeEmulation bool                        `json:"one_time_emulation"`
+		Speed            float64                     `json:"speed"`
+		Loop             loopSegment                 `json:"loop"`
+		ResetPolicy      string                      `json:"reset_policy"`
+		WritePolicy      structs.WritePolicySettings `json:"write_policy"`
+		Forces           []structs.ForcedRange       `json:"forces"`
+		StartTime        string                      `json:"start_time"`
+		EndTime          string                      `json:"end_time"`
+		CurrentTime      string                      `json:"current_time"`
 	}
 	settingsResponse struct {
 		ID       int                     `json:"id"`
@@ -50,6 +57,23 @@ type (
 		Error           string `json:"error"`
 		SettedTimepoint string `json:"setted_timepoint"`
 	}
+	seekResponse struct {
+		ID               int    `json:"id"`
+		Error            string `json:"error,omitempty"`
+		TransactionIndex int    `json:"transaction_index"`
+		Timepoint        string `json:"timepoint"`
+	}
+	registersResponse struct {
+		ServerID   int      `json:"server_id"`
+		SlaveID    uint8    `json:"slave_id"`
+		ObjectType string   `json:"object_type"`
+		From       uint16   `json:"from"`
+		Count      int      `json:"count"`
+		Values     []uint16 `json:"values"`
+	}
+	registersPayload struct {
+		Values []uint16 `json:"values"`
+	}
 	slaveResponse struct {
 		ServerID        int    `json:"server_id"`
 		AnswerwedSlaves []int8 `json:"answered_slaves"`
@@ -64,14 +88,20 @@ type (
 var (
 	emulationServers struct {
 		readWriteMutex           sync.RWMutex
+		registersMutex           sync.RWMutex
 		serversData              []emulationServerSettings
 		servers                  []*ms.Server
 		rewindChannels           []chan (int)
-		emulationControlChannels []chan (bool)
+		emulationControlChannels []chan (emulationCommand)
+		speedChannels            []chan (bool)
+		debugStates              []debugState
+		clientWrites             []structs.RegistersImage
+		writeEvents              [][]writeEvent
 	}
 
-	boolStringValues = map[string]bool{"true": true, "false": false, "start": true, "stop": false}
-	errorHeader      = "Error on HTTP-request"
+	registersObjectTypes = map[string]string{"coils": conf.ObjectTypes.Coils, "di": conf.ObjectTypes.DI, "hr": conf.ObjectTypes.HR, "ir": conf.ObjectTypes.IR}
+	boolStringValues     = map[string]bool{"true": true, "false": false, "start": true, "stop": false}
+	errorHeader          = "Error on HTTP-request"
 )
 
 func StartHTTPServer() {
@@ -85,12 +115,42 @@ func StartHTTPServer() {
 			settings.POST("emulation_mode", setEmulationMode)
 			settings.POST("slave_answer", setSlaveState)
 			settings.POST("emulation_control", controlEmulation)
+			settings.POST("playback_speed", setPlaybackSpeed)
+			settings.POST("loop", setLoopSegment)
+			settings.POST("reset_policy", setResetPolicy)
+			settings.POST("write_policy", setWritePolicy)
+			settings.DELETE("write_policy", deleteWritePolicyRanges)
+			settings.POST("force", forceRegisters)
+			settings.DELETE("force", unforceRegisters)
 		}
 		time := emulator.Group("time")
 		{
 			time.GET("actual", getActualTime)
 			time.GET("start&end", getStartEndTime)
 			time.POST("rewind_emulation", rewindServersEmulation)
+			time.POST("seek", seekServersEmulation)
+		}
+		debug := emulator.Group("debug")
+		{
+			debug.GET("", getDebugState)
+			debug.POST("pause", pauseEmulation)
+			debug.POST("step", stepEmulation)
+			debug.POST("run", runEmulation)
+			debug.POST("breakpoints", addBreakpoint)
+			debug.DELETE("breakpoints", deleteBreakpoints)
+		}
+		emulator.GET("verification", getVerificationReport)
+		emulator.GET("write_events", getWriteEvents)
+		servers := emulator.Group("servers")
+		{
+			servers.GET(":id/slaves/:slave/:object_type", getRegisters)
+			servers.PUT(":id/slaves/:slave/:object_type", setRegisters)
+		}
+		tags := emulator.Group("tags")
+		{
+			tags.GET("", getTags)
+			tags.GET(":name", getTag)
+			tags.PUT(":name", setTag)
 		}
 		emulator.GET("/", func(gctx *gin.Context) {
 			gctx.Redirect(http.StatusPermanentRedirect,
@@ -190,6 +250,407 @@ func setEmulationMode(gctx *gin.Context) {
 	gctx.JSON(http.StatusOK, response)
 }
 
+func setPlaybackSpeed(gctx *gin.Context) {
+	var err error
+	var speed string
+	var ok bool
+	if speed, ok = gctx.GetQuery("speed"); !ok {
+		err = fmt.Errorf("missed required \"speed\" parameter")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+		return
+	}
+	var factor float64
+	if factor, err = conf.ParsePlaybackSpeed(speed); err != nil {
+		err = fmt.Errorf("invalid \"speed\" parameter: %s", err)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	serversData := getSettingsBuffer()
+	leftBorder, rightBorder := 0, len(serversData)
+	if id, ok := gctx.GetQuery("server_id"); ok {
+		var serverID int
+		if serverID, err = strconv.Atoi(id); err != nil {
+			log.Printf("%s: invalid \"server_id\" parameter - %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{`Invalid "server_id" parameter`: err.Error()})
+			return
+		}
+		if serverID > len(serversData)-1 || serverID < 0 {
+			log.Printf("Error on HTTP-request: \"server_id\" parameter must be in range [0:%d]", len(serversData))
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{`"server" parameter must be in range`: fmt.Sprintf("[0:%d]", len(serversData))})
+			return
+		}
+		leftBorder, rightBorder = serverID, serverID+1
+	}
+	var response []settingsResponse
+	emulationServers.readWriteMutex.Lock()
+	for currentID := leftBorder; currentID < rightBorder; currentID++ {
+		emulationServers.serversData[currentID].Speed = factor
+		select {
+		case emulationServers.speedChannels[currentID] <- true:
+		default:
+		}
+		response = append(response, settingsResponse{
+			ID:       currentID,
+			Settings: emulationServers.serversData[currentID],
+		})
+	}
+	emulationServers.readWriteMutex.Unlock()
+	log.Printf("Playback speed of servers [%d:%d] set to %s", leftBorder, rightBorder, conf.FormatPlaybackSpeed(factor))
+	gctx.JSON(http.StatusOK, response)
+}
+
+func setLoopSegment(gctx *gin.Context) {
+	var err error
+	inTime, outTime := gctx.Query("in_time"), gctx.Query("out_time")
+	for _, currentTime := range []string{inTime, outTime} {
+		if _, err = conf.ParseLoopTime(currentTime); err != nil {
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+	}
+	serversData := getSettingsBuffer()
+	leftBorder, rightBorder := 0, len(serversData)
+	if _, ok := gctx.GetQuery("server_id"); ok {
+		var serverID int
+		if serverID, ok = getRequiredServerID(gctx); !ok {
+			return
+		}
+		leftBorder, rightBorder = serverID, serverID+1
+	}
+	segments := make([]loopSegment, rightBorder-leftBorder)
+	for currentID := leftBorder; currentID < rightBorder; currentID++ {
+		if segments[currentID-leftBorder], err = newLoopSegment(History[serversData[currentID].RealSocket], inTime, outTime); err != nil {
+			err = fmt.Errorf("invalid loop segment of %d server: %s", currentID, err)
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+	}
+	var response []settingsResponse
+	emulationServers.readWriteMutex.Lock()
+	for currentID := leftBorder; currentID < rightBorder; currentID++ {
+		emulationServers.serversData[currentID].Loop = segments[currentID-leftBorder]
+		response = append(response, settingsResponse{
+			ID:       currentID,
+			Settings: emulationServers.serversData[currentID],
+		})
+	}
+	emulationServers.readWriteMutex.Unlock()
+	if inTime == "" && outTime == "" {
+		log.Printf("Loop segment of servers [%d:%d] has been disabled", leftBorder, rightBorder)
+	} else {
+		log.Printf("Loop segment of servers [%d:%d] set to [%s:%s]", leftBorder, rightBorder, inTime, outTime)
+	}
+	gctx.JSON(http.StatusOK, response)
+}
+
+func setResetPolicy(gctx *gin.Context) {
+	var err error
+	var policy string
+	var ok bool
+	if policy, ok = gctx.GetQuery("policy"); !ok {
+		err = fmt.Errorf("missed required \"policy\" parameter")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+		return
+	}
+	if !slices.Contains([]string{conf.ResetPolicies.Keep, conf.ResetPolicies.Zero, conf.ResetPolicies.Snapshot}, policy) {
+		err = fmt.Errorf("invalid \"policy\" parameter: %s", policy)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	serversData := getSettingsBuffer()
+	leftBorder, rightBorder := 0, len(serversData)
+	if _, ok = gctx.GetQuery("server_id"); ok {
+		var serverID int
+		if serverID, ok = getRequiredServerID(gctx); !ok {
+			return
+		}
+		leftBorder, rightBorder = serverID, serverID+1
+	}
+	var response []settingsResponse
+	emulationServers.readWriteMutex.Lock()
+	for currentID := leftBorder; currentID < rightBorder; currentID++ {
+		emulationServers.serversData[currentID].ResetPolicy = policy
+		response = append(response, settingsResponse{
+			ID:       currentID,
+			Settings: emulationServers.serversData[currentID],
+		})
+	}
+	emulationServers.readWriteMutex.Unlock()
+	log.Printf("Reset policy of servers [%d:%d] set to %s", leftBorder, rightBorder, policy)
+	gctx.JSON(http.StatusOK, response)
+}
+
+func setWritePolicy(gctx *gin.Context) {
+	var err error
+	var policy string
+	var ok bool
+	if policy, ok = gctx.GetQuery("policy"); !ok {
+		err = fmt.Errorf("missed required \"policy\" parameter")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+		return
+	}
+	if !slices.Contains([]string{conf.WritePolicies.Keep, conf.WritePolicies.Dump, conf.WritePolicies.Reject}, policy) {
+		err = fmt.Errorf("invalid \"policy\" parameter: %s", policy)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	exception := conf.WriteRejectException
+	if value, isSet := gctx.GetQuery("exception"); isSet {
+		var number uint64
+		if number, err = strconv.ParseUint(value, 10, 8); err != nil || number == 0 {
+			err = fmt.Errorf("invalid \"exception\" parameter (must be Modbus exception code in range [1:255])")
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+		exception = uint8(number)
+	}
+	var policyRange *structs.WritePolicyRange
+	if _, isSet := gctx.GetQuery("slave_id"); isSet {
+		var addressRange structs.RegisterRange
+		if addressRange, err = parseRegisterRange(gctx, []string{conf.ObjectTypes.Coils, conf.ObjectTypes.HR}); err != nil {
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+		policyRange = &structs.WritePolicyRange{RegisterRange: addressRange, Policy: policy, Exception: exception}
+	}
+	serversData := getSettingsBuffer()
+	leftBorder, rightBorder := 0, len(serversData)
+	if _, ok = gctx.GetQuery("server_id"); ok {
+		var serverID int
+		if serverID, ok = getRequiredServerID(gctx); !ok {
+			return
+		}
+		leftBorder, rightBorder = serverID, serverID+1
+	}
+	var response []settingsResponse
+	emulationServers.readWriteMutex.Lock()
+	for currentID := leftBorder; currentID < rightBorder; currentID++ {
+		writePolicy := &emulationServers.serversData[currentID].WritePolicy
+		if policyRange == nil {
+			writePolicy.Policy, writePolicy.Exception = policy, exception
+		} else {
+			writePolicy.Ranges = slices.DeleteFunc(slices.Clone(writePolicy.Ranges), func(currentRange structs.WritePolicyRange) bool {
+				return currentRange.RegisterRange == policyRange.RegisterRange
+			})
+			writePolicy.Ranges = append(writePolicy.Ranges, *policyRange)
+		}
+		pruneClientWrites(currentID)
+		response = append(response, settingsResponse{
+			ID:       currentID,
+			Settings: emulationServers.serversData[currentID],
+		})
+	}
+	emulationServers.readWriteMutex.Unlock()
+	if policyRange == nil {
+		log.Printf("Write policy of servers [%d:%d] set to %s", leftBorder, rightBorder, policy)
+	} else {
+		log.Printf("Write policy of %s[%d:%d] of slave %d on servers [%d:%d] set to %s",
+			policyRange.ObjectType, policyRange.Start, policyRange.End, policyRange.SlaveID, leftBorder, rightBorder, policy)
+	}
+	gctx.JSON(http.StatusOK, response)
+}
+
+func deleteWritePolicyRanges(gctx *gin.Context) {
+	serversData := getSettingsBuffer()
+	leftBorder, rightBorder := 0, len(serversData)
+	if _, ok := gctx.GetQuery("server_id"); ok {
+		var serverID int
+		if serverID, ok = getRequiredServerID(gctx); !ok {
+			return
+		}
+		leftBorder, rightBorder = serverID, serverID+1
+	}
+	var response []settingsResponse
+	emulationServers.readWriteMutex.Lock()
+	for currentID := leftBorder; currentID < rightBorder; currentID++ {
+		emulationServers.serversData[currentID].WritePolicy.Ranges = nil
+		pruneClientWrites(currentID)
+		response = append(response, settingsResponse{
+			ID:       currentID,
+			Settings: emulationServers.serversData[currentID],
+		})
+	}
+	emulationServers.readWriteMutex.Unlock()
+	log.Printf("Write policy ranges of servers [%d:%d] have been deleted", leftBorder, rightBorder)
+	gctx.JSON(http.StatusOK, response)
+}
+
+func forceRegisters(gctx *gin.Context) {
+	serverID, ok := getRequiredServerID(gctx)
+	if !ok {
+		return
+	}
+	var err error
+	var force structs.ForcedRange
+	if name, isSet := gctx.GetQuery("tag"); isSet {
+		var tag registermap.Tag
+		if tag, ok = RegisterMap.Find(name); !ok {
+			err = fmt.Errorf("tag %s isn't found in register map", name)
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusNotFound, gin.H{errorHeader: err.Error()})
+			return
+		}
+		if tag.Socket != "" && tag.Socket != getSettingsBuffer()[serverID].RealSocket {
+			err = fmt.Errorf("tag %s isn't served by %d server", tag.Name, serverID)
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+		var value string
+		if value, ok = gctx.GetQuery("value"); !ok {
+			err = fmt.Errorf("missed required \"value\" parameter")
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+			return
+		}
+		if force.Values, err = tag.Encode(value); err != nil {
+			err = fmt.Errorf("invalid \"value\" parameter: %s", err)
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+		force.RegisterRange = structs.RegisterRange{SlaveID: tag.Slave, ObjectType: tag.ObjectType, Start: tag.Address, End: tag.Address + uint16(len(force.Values)) - 1}
+	} else {
+		if force.RegisterRange, err = parseRegisterRange(gctx, []string{conf.ObjectTypes.Coils, conf.ObjectTypes.DI, conf.ObjectTypes.HR, conf.ObjectTypes.IR}); err != nil {
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+		var code int
+		if force.Values, code, err = parseForcedValues(gctx, force.RegisterRange); err != nil {
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(code, gin.H{errorHeader: err.Error()})
+			return
+		}
+	}
+	emulationServers.readWriteMutex.Lock()
+	server := emulationServers.servers[serverID]
+	if _, ok = server.Slaves[force.SlaveID]; !ok {
+		emulationServers.readWriteMutex.Unlock()
+		err = fmt.Errorf("slave %d isn't initialized on %d server", force.SlaveID, serverID)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	forces := structs.UnforceRange(emulationServers.serversData[serverID].Forces, force.RegisterRange)
+	emulationServers.serversData[serverID].Forces = append(forces, force)
+	response := []settingsResponse{{ID: serverID, Settings: emulationServers.serversData[serverID]}}
+	emulationServers.readWriteMutex.Unlock()
+	emulationServers.registersMutex.Lock()
+	applyForce(server, force)
+	emulationServers.registersMutex.Unlock()
+	log.Printf("%s[%d:%d] of slave %d on %d server forced to %v", force.ObjectType, force.Start, force.End, force.SlaveID, serverID, force.Values)
+	gctx.JSON(http.StatusOK, response)
+}
+
+func parseForcedValues(gctx *gin.Context, addressRange structs.RegisterRange) (values []uint16, code int, err error) {
+	count := int(addressRange.End) - int(addressRange.Start) + 1
+	var rawValues []string
+	if value, ok := gctx.GetQuery("value"); ok {
+		for range count {
+			rawValues = append(rawValues, value)
+		}
+	} else if value, ok = gctx.GetQuery("values"); ok {
+		if rawValues = strings.Split(value, ","); len(rawValues) != count {
+			err = fmt.Errorf("\"values\" parameter count (%d) isn't equal to range length (%d)", len(rawValues), count)
+			return nil, http.StatusUnprocessableEntity, err
+		}
+	} else {
+		err = fmt.Errorf("missed required \"value\", \"values\" or \"tag\" parameter")
+		return nil, http.StatusBadRequest, err
+	}
+	isBits := addressRange.ObjectType == conf.ObjectTypes.Coils || addressRange.ObjectType == conf.ObjectTypes.DI
+	for _, currentValue := range rawValues {
+		number, err := strconv.ParseUint(strings.TrimSpace(currentValue), 10, 16)
+		if err != nil || isBits && number > 1 {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("invalid forced value: %s", currentValue)
+		}
+		values = append(values, uint16(number))
+	}
+	return
+}
+
+func unforceRegisters(gctx *gin.Context) {
+	serverID, ok := getRequiredServerID(gctx)
+	if !ok {
+		return
+	}
+	var addressRange *structs.RegisterRange
+	if _, isSet := gctx.GetQuery("slave_id"); isSet {
+		parsedRange, err := parseRegisterRange(gctx, []string{conf.ObjectTypes.Coils, conf.ObjectTypes.DI, conf.ObjectTypes.HR, conf.ObjectTypes.IR})
+		if err != nil {
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+		addressRange = &parsedRange
+	}
+	emulationServers.readWriteMutex.Lock()
+	if addressRange == nil {
+		emulationServers.serversData[serverID].Forces = nil
+	} else {
+		emulationServers.serversData[serverID].Forces = structs.UnforceRange(emulationServers.serversData[serverID].Forces, *addressRange)
+	}
+	response := []settingsResponse{{ID: serverID, Settings: emulationServers.serversData[serverID]}}
+	emulationServers.readWriteMutex.Unlock()
+	if addressRange == nil {
+		log.Printf("All forces of %d server have been removed", serverID)
+	} else {
+		log.Printf("%s[%d:%d] of slave %d on %d server unforced", addressRange.ObjectType, addressRange.Start, addressRange.End, addressRange.SlaveID, serverID)
+	}
+	gctx.JSON(http.StatusOK, response)
+}
+
+func parseRegisterRange(gctx *gin.Context, objectTypes []string) (addressRange structs.RegisterRange, err error) {
+	parseNumber := func(name string, bitSize int) (number uint64, err error) {
+		value, ok := gctx.GetQuery(name)
+		if !ok {
+			err = fmt.Errorf("missed required \"%s\" parameter", name)
+			return
+		}
+		if number, err = strconv.ParseUint(value, 10, bitSize); err != nil {
+			err = fmt.Errorf("invalid \"%s\" parameter: %s", name, err)
+		}
+		return
+	}
+	var number uint64
+	if number, err = parseNumber("slave_id", 8); err != nil {
+		return
+	}
+	addressRange.SlaveID = uint8(number)
+	var ok bool
+	if addressRange.ObjectType, ok = gctx.GetQuery("object_type"); !ok {
+		err = fmt.Errorf("missed required \"object_type\" parameter")
+		return
+	}
+	if !slices.Contains(objectTypes, addressRange.ObjectType) {
+		err = fmt.Errorf("invalid \"object_type\" parameter: %s (must be one of %v)", addressRange.ObjectType, objectTypes)
+		return
+	}
+	if number, err = parseNumber("start", 16); err != nil {
+		return
+	}
+	addressRange.Start = uint16(number)
+	if number, err = parseNumber("end", 16); err != nil {
+		return
+	}
+	addressRange.End = uint16(number)
+	if addressRange.End < addressRange.Start {
+		err = fmt.Errorf("\"end\" parameter must not be less than \"start\" parameter")
+	}
+	return
+}
+
 func setSlaveState(gctx *gin.Context) {
 	var err error
 	serversData := getSettingsBuffer()
@@ -305,9 +766,10 @@ func controlEmulation(gctx *gin.Context) {
 			return
 		}
 		emulationServers.readWriteMutex.RLock()
-		defer emulationServers.readWriteMutex.RUnlock()
+		emulationControlChannel := emulationServers.emulationControlChannels[serverID]
+		emulationServers.readWriteMutex.RUnlock()
 		select {
-		case emulationServers.emulationControlChannels[serverID] <- true:
+		case emulationControlChannel <- emulationCommand{action: flag}:
 			response = append(response, emulationControlResponse{
 				ID:          serverID,
 				IsEmulating: isEmulating,
@@ -330,9 +792,10 @@ func controlEmulation(gctx *gin.Context) {
 			currentResponse.IsEmulating = currentData.IsEmulating
 		} else {
 			emulationServers.readWriteMutex.RLock()
-			defer emulationServers.readWriteMutex.RUnlock()
+			emulationControlChannel := emulationServers.emulationControlChannels[currentID]
+			emulationServers.readWriteMutex.RUnlock()
 			select {
-			case emulationServers.emulationControlChannels[currentID] <- true:
+			case emulationControlChannel <- emulationCommand{action: flag}:
 				currentResponse.IsEmulating = isEmulating
 			case <-time.After(time.Second):
 				err = fmt.Errorf("invalid \"emulation_switch\" parameter: server couldn't process state (emulation isn't initialized)")
@@ -462,12 +925,7 @@ func rewindServersEmulation(gctx *gin.Context) {
 			gctx.JSON(httpCode, response)
 			return
 		}
-		transactionIndex := -1
-		for currentIndex, currentTransaction := range History[serversData[serverID].DumpSocketsConfigData.RealSocket].Transactions {
-			if timepoint.After(currentTransaction.TransactionTime) {
-				transactionIndex = currentIndex
-			}
-		}
+		transactionIndex := History[serversData[serverID].DumpSocketsConfigData.RealSocket].SearchTransaction(timepoint)
 		if transactionIndex == -1 {
 			err := fmt.Errorf("error on detected atcual rewind timepoint")
 			log.Print(err.Error())
@@ -476,10 +934,15 @@ func rewindServersEmulation(gctx *gin.Context) {
 			gctx.JSON(http.StatusUnprocessableEntity, response)
 			return
 		}
+		if err = sendRewind(serverID, transactionIndex); err != nil {
+			err = fmt.Errorf("%s: %s", errorHeader, err)
+			log.Print(err)
+			responseValue.Error = err.Error()
+			response = append(response, responseValue)
+			gctx.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
 		responseValue.SettedTimepoint = timepoint.String()
-		emulationServers.readWriteMutex.RLock()
-		emulationServers.rewindChannels[serverID] <- transactionIndex
-		emulationServers.readWriteMutex.RUnlock()
 		logString := fmt.Sprintf("Successfully rewinded %d server to %s timepoint", serverID, timepoint.String())
 		log.Print(logString)
 		response = append(response, responseValue)
@@ -497,12 +960,7 @@ func rewindServersEmulation(gctx *gin.Context) {
 			response = append(response, currentResponse)
 			continue
 		}
-		currentTransactionIndex := -1
-		for currentIndex, currentTransaction := range History[currentServerData.DumpSocketsConfigData.RealSocket].Transactions {
-			if timepoint.After(currentTransaction.TransactionTime) {
-				currentTransactionIndex = currentIndex
-			}
-		}
+		currentTransactionIndex := History[currentServerData.DumpSocketsConfigData.RealSocket].SearchTransaction(timepoint)
 		if currentTransactionIndex == -1 {
 			err = fmt.Errorf("%s: error on detected atcual rewind timepoint", errorHeader)
 			log.Print(err)
@@ -510,17 +968,564 @@ func rewindServersEmulation(gctx *gin.Context) {
 			response = append(response, currentResponse)
 			continue
 		}
-		currentResponse.SettedTimepoint = timepoint.String()
-		emulationServers.readWriteMutex.RLock()
-		emulationServers.rewindChannels[currentIndex] <- currentTransactionIndex
-		emulationServers.readWriteMutex.RUnlock()
-		logString := fmt.Sprintf("Successfully rewinded %d server to %s timepoint", currentIndex, timepoint.String())
-		log.Print(logString)
+		if err = sendRewind(currentIndex, currentTransactionIndex); err != nil {
+			err = fmt.Errorf("%s: %s", errorHeader, err)
+			log.Print(err)
+			currentResponse.Error = err.Error()
+			response = append(response, currentResponse)
+			continue
+		}
+		currentResponse.SettedTimepoint = timepoint.String()
+		logString := fmt.Sprintf("Successfully rewinded %d server to %s timepoint", currentIndex, timepoint.String())
+		log.Print(logString)
+		response = append(response, currentResponse)
+	}
+	gctx.JSON(http.StatusOK, response)
+}
+
+func seekServersEmulation(gctx *gin.Context) {
+	var err error
+	parameters := make(map[string]string)
+	for _, currentName := range []string{"mode", "value"} {
+		var ok bool
+		if parameters[currentName], ok = gctx.GetQuery(currentName); !ok {
+			err = fmt.Errorf("missed required \"%s\" parameter", currentName)
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+			return
+		}
+	}
+	if !slices.Contains([]string{conf.SeekModes.TransactionIndex, conf.SeekModes.Offset, conf.SeekModes.Percentage, conf.SeekModes.Position}, parameters["mode"]) {
+		err = fmt.Errorf("invalid \"mode\" parameter: %s", parameters["mode"])
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	serversData := getSettingsBuffer()
+	var serverIDs []int
+	_, isSingleServer := gctx.GetQuery("server_id")
+	if isSingleServer {
+		serverID, ok := getRequiredServerID(gctx)
+		if !ok {
+			return
+		}
+		serverIDs = append(serverIDs, serverID)
+	} else {
+		for currentID := range serversData {
+			serverIDs = append(serverIDs, currentID)
+		}
+	}
+	var response []seekResponse
+	for _, currentID := range serverIDs {
+		currentResponse := seekResponse{ID: currentID}
+		var httpCode int
+		if httpCode, err = seekServerEmulation(currentID, serversData[currentID], parameters["mode"], parameters["value"], &currentResponse); err != nil {
+			err = fmt.Errorf("%s: %s", errorHeader, err)
+			log.Print(err)
+			currentResponse.Error = err.Error()
+			if isSingleServer {
+				gctx.JSON(httpCode, []seekResponse{currentResponse})
+				return
+			}
+		}
 		response = append(response, currentResponse)
 	}
 	gctx.JSON(http.StatusOK, response)
 }
 
+func seekServerEmulation(serverID int, serverData emulationServerSettings, mode, value string, response *seekResponse) (httpCode int, err error) {
+	httpCode = http.StatusUnprocessableEntity
+	if !serverData.IsWorking {
+		return httpCode, fmt.Errorf("current server isn't working")
+	}
+	if serverData.CurrentTime == "" {
+		return httpCode, fmt.Errorf("current server isn't emulating data")
+	}
+	history := History[serverData.DumpSocketsConfigData.RealSocket]
+	emulationServers.readWriteMutex.RLock()
+	currentIndex := emulationServers.debugStates[serverID].TransactionIndex
+	emulationServers.readWriteMutex.RUnlock()
+	if response.TransactionIndex, err = structs.SeekTransaction(history, currentIndex, mode, value); err != nil {
+		return
+	}
+	var timepoint time.Time
+	if timepoint, err = history.GetTransactionTime(response.TransactionIndex); err != nil {
+		return
+	}
+	response.Timepoint = timepoint.String()
+	if err = sendRewind(serverID, response.TransactionIndex); err != nil {
+		return http.StatusServiceUnavailable, err
+	}
+	log.Printf("Successfully seeked %d server to transaction %d (%s)", serverID, response.TransactionIndex, response.Timepoint)
+	return http.StatusOK, nil
+}
+
+func sendRewind(serverID, transactionIndex int) (err error) {
+	emulationServers.readWriteMutex.RLock()
+	rewindChannel := emulationServers.rewindChannels[serverID]
+	emulationServers.readWriteMutex.RUnlock()
+	select {
+	case rewindChannel <- transactionIndex:
+	case <-time.After(conf.RewindTimeout):
+		err = fmt.Errorf("server hasn't accepted rewind during %s", conf.RewindTimeout)
+	}
+	return
+}
+
+func getVerificationReport(gctx *gin.Context) {
+	if Verifier == nil {
+		err := fmt.Errorf("verification isn't enabled")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	gctx.JSON(http.StatusOK, Verifier.Report())
+}
+
+func getWriteEvents(gctx *gin.Context) {
+	serversData := getSettingsBuffer()
+	leftBorder, rightBorder := 0, len(serversData)
+	if _, ok := gctx.GetQuery("server_id"); ok {
+		var serverID int
+		if serverID, ok = getRequiredServerID(gctx); !ok {
+			return
+		}
+		leftBorder, rightBorder = serverID, serverID+1
+	}
+	response := []writeEvent{}
+	emulationServers.readWriteMutex.RLock()
+	for _, currentEvents := range emulationServers.writeEvents[leftBorder:rightBorder] {
+		response = append(response, currentEvents...)
+	}
+	emulationServers.readWriteMutex.RUnlock()
+	gctx.JSON(http.StatusOK, response)
+}
+
+func getRegisters(gctx *gin.Context) {
+	response, server, ok := getRegistersRange(gctx, 1)
+	if !ok {
+		return
+	}
+	emulationServers.registersMutex.RLock()
+	response.Values = readRegisters(server.Slaves[response.SlaveID], response.ObjectType, int(response.From), int(response.From)+response.Count)
+	emulationServers.registersMutex.RUnlock()
+	gctx.JSON(http.StatusOK, response)
+}
+
+func readRegisters(slave ms.SlaveData, objectType string, leftBorder, rightBorder int) (values []uint16) {
+	switch objectType {
+	case conf.ObjectTypes.Coils:
+		for _, currentValue := range slave.Coils[leftBorder:rightBorder] {
+			values = append(values, uint16(currentValue))
+		}
+	case conf.ObjectTypes.DI:
+		for _, currentValue := range slave.DiscreteInputs[leftBorder:rightBorder] {
+			values = append(values, uint16(currentValue))
+		}
+	case conf.ObjectTypes.HR:
+		values = slices.Clone(slave.HoldingRegisters[leftBorder:rightBorder])
+	case conf.ObjectTypes.IR:
+		values = slices.Clone(slave.InputRegisters[leftBorder:rightBorder])
+	}
+	return
+}
+
+func setRegisters(gctx *gin.Context) {
+	var err error
+	var payload registersPayload
+	if err = gctx.ShouldBindJSON(&payload); err != nil {
+		err = fmt.Errorf("invalid payload: %s", err)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+		return
+	}
+	if len(payload.Values) == 0 {
+		err = fmt.Errorf("payload \"values\" must not be empty")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+		return
+	}
+	response, server, ok := getRegistersRange(gctx, len(payload.Values))
+	if !ok {
+		return
+	}
+	if err = structs.CheckRegistersValues(response.ObjectType, response.Count, payload.Values); err != nil {
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	slave := server.Slaves[response.SlaveID]
+	emulationServers.registersMutex.Lock()
+	switch response.ObjectType {
+	case conf.ObjectTypes.Coils:
+		copy(slave.Coils[response.From:], sliceUint16ToByte(payload.Values))
+	case conf.ObjectTypes.DI:
+		copy(slave.DiscreteInputs[response.From:], sliceUint16ToByte(payload.Values))
+	case conf.ObjectTypes.HR:
+		copy(slave.HoldingRegisters[response.From:], payload.Values)
+	case conf.ObjectTypes.IR:
+		copy(slave.InputRegisters[response.From:], payload.Values)
+	}
+	pinRegisters(server)
+	response.Values = readRegisters(slave, response.ObjectType, int(response.From), int(response.From)+response.Count)
+	emulationServers.registersMutex.Unlock()
+	log.Printf("%s[%d:%d] of slave %d on %d server set to %v", response.ObjectType, response.From, int(response.From)+response.Count, response.SlaveID, response.ServerID, response.Values)
+	gctx.JSON(http.StatusOK, response)
+}
+
+func getRegistersRange(gctx *gin.Context, defaultCount int) (response registersResponse, server *ms.Server, ok bool) {
+	var err error
+	serversData := getSettingsBuffer()
+	if response.ServerID, err = strconv.Atoi(gctx.Param("id")); err != nil || response.ServerID > len(serversData)-1 || response.ServerID < 0 {
+		err = fmt.Errorf("invalid server id %s (must be in range [0:%d])", gctx.Param("id"), len(serversData)-1)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusNotFound, gin.H{errorHeader: err.Error()})
+		return
+	}
+	var number uint64
+	if number, err = strconv.ParseUint(gctx.Param("slave"), 10, 8); err != nil {
+		err = fmt.Errorf("invalid slave id %s", gctx.Param("slave"))
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusNotFound, gin.H{errorHeader: err.Error()})
+		return
+	}
+	response.SlaveID = uint8(number)
+	if response.ObjectType, ok = registersObjectTypes[gctx.Param("object_type")]; !ok {
+		err = fmt.Errorf("invalid object type %s (must be one of %v)", gctx.Param("object_type"), maps.Keys(registersObjectTypes))
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusNotFound, gin.H{errorHeader: err.Error()})
+		return
+	}
+	emulationServers.readWriteMutex.RLock()
+	server = emulationServers.servers[response.ServerID]
+	emulationServers.readWriteMutex.RUnlock()
+	var slave ms.SlaveData
+	if slave, ok = server.Slaves[response.SlaveID]; !ok {
+		err = fmt.Errorf("slave %d isn't initialized on %d server", response.SlaveID, response.ServerID)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusNotFound, gin.H{errorHeader: err.Error()})
+		return
+	}
+	ok = false
+	if from, isSet := gctx.GetQuery("from"); isSet {
+		if number, err = strconv.ParseUint(from, 10, 16); err != nil {
+			err = fmt.Errorf("invalid \"from\" parameter: %s", err)
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+		response.From = uint16(number)
+	}
+	response.Count = defaultCount
+	if count, isSet := gctx.GetQuery("count"); isSet {
+		if response.Count, err = strconv.Atoi(count); err != nil {
+			err = fmt.Errorf("invalid \"count\" parameter (must be positive integer)")
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+	}
+	size := map[string]int{
+		conf.ObjectTypes.Coils: len(slave.Coils),
+		conf.ObjectTypes.DI:    len(slave.DiscreteInputs),
+		conf.ObjectTypes.HR:    len(slave.HoldingRegisters),
+		conf.ObjectTypes.IR:    len(slave.InputRegisters),
+	}[response.ObjectType]
+	if err = structs.CheckRegistersRange(response.ObjectType, response.From, response.Count, size); err != nil {
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	return response, server, true
+}
+
+func getTags(gctx *gin.Context) {
+	serversData := getSettingsBuffer()
+	leftBorder, rightBorder := 0, len(serversData)
+	if id, ok := gctx.GetQuery("server_id"); ok {
+		var idInt int
+		var err error
+		if idInt, err = strconv.Atoi(id); err != nil {
+			log.Printf("%s: invalid \"server_id\" parameter - %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{`Invalid "server_id" parameter`: err.Error()})
+			return
+		}
+		if idInt > len(serversData)-1 || idInt < 0 {
+			log.Printf("Error on HTTP-request: \"server_id\" parameter must be in range [0:%d]", len(serversData))
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{`"server" parameter must be in range`: fmt.Sprintf("[0:%d]", len(serversData))})
+			return
+		}
+		leftBorder, rightBorder = idInt, idInt+1
+	}
+	response := []tagValue{}
+	emulationServers.registersMutex.RLock()
+	emulationServers.readWriteMutex.RLock()
+	for currentID := leftBorder; currentID < rightBorder; currentID++ {
+		for _, currentTag := range RegisterMap.ServerTags(serversData[currentID].RealSocket) {
+			response = append(response, readTagValue(currentID, emulationServers.servers[currentID], currentTag))
+		}
+	}
+	emulationServers.readWriteMutex.RUnlock()
+	emulationServers.registersMutex.RUnlock()
+	gctx.JSON(http.StatusOK, response)
+}
+
+func getDebugState(gctx *gin.Context) {
+	serversData := getSettingsBuffer()
+	leftBorder, rightBorder := 0, len(serversData)
+	if _, ok := gctx.GetQuery("server_id"); ok {
+		var serverID int
+		if serverID, ok = getRequiredServerID(gctx); !ok {
+			return
+		}
+		leftBorder, rightBorder = serverID, serverID+1
+	}
+	var response []debugResponse
+	for currentID := leftBorder; currentID < rightBorder; currentID++ {
+		response = append(response, getDebugResponse(currentID))
+	}
+	gctx.JSON(http.StatusOK, response)
+}
+
+func pauseEmulation(gctx *gin.Context) {
+	if serverID, ok := getRequiredServerID(gctx); ok {
+		sendEmulationCommand(gctx, serverID, emulationCommand{action: emulationActions.Stop})
+	}
+}
+
+func stepEmulation(gctx *gin.Context) {
+	serverID, ok := getRequiredServerID(gctx)
+	if !ok {
+		return
+	}
+	steps := 1
+	if count, ok := gctx.GetQuery("count"); ok {
+		var err error
+		if steps, err = strconv.Atoi(count); err != nil || steps < 1 {
+			err = fmt.Errorf("invalid \"count\" parameter (must be positive integer)")
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+	}
+	sendEmulationCommand(gctx, serverID, emulationCommand{action: emulationActions.Step, steps: steps})
+}
+
+func runEmulation(gctx *gin.Context) {
+	if serverID, ok := getRequiredServerID(gctx); ok {
+		sendEmulationCommand(gctx, serverID, emulationCommand{action: emulationActions.Start})
+	}
+}
+
+func addBreakpoint(gctx *gin.Context) {
+	serverID, ok := getRequiredServerID(gctx)
+	if !ok {
+		return
+	}
+	var kind string
+	if kind, ok = gctx.GetQuery("kind"); !ok {
+		err := fmt.Errorf("missed required \"kind\" parameter")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+		return
+	}
+	parameters := make(map[string]string)
+	for currentName, currentValues := range gctx.Request.URL.Query() {
+		parameters[currentName] = currentValues[0]
+	}
+	newBreakpoint, err := newBreakpoint(kind, parameters)
+	if err != nil {
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	emulationServers.readWriteMutex.Lock()
+	emulationServers.debugStates[serverID].breakpointsCounter++
+	newBreakpoint.ID = emulationServers.debugStates[serverID].breakpointsCounter
+	emulationServers.debugStates[serverID].Breakpoints = append(emulationServers.debugStates[serverID].Breakpoints, newBreakpoint)
+	emulationServers.readWriteMutex.Unlock()
+	log.Printf("Breakpoint %d (%s) added to %d server", newBreakpoint.ID, newBreakpoint.Kind, serverID)
+	gctx.JSON(http.StatusOK, []debugResponse{getDebugResponse(serverID)})
+}
+
+func deleteBreakpoints(gctx *gin.Context) {
+	serverID, ok := getRequiredServerID(gctx)
+	if !ok {
+		return
+	}
+	id, isSet := gctx.GetQuery("breakpoint_id")
+	emulationServers.readWriteMutex.Lock()
+	if !isSet {
+		emulationServers.debugStates[serverID].Breakpoints = nil
+	} else {
+		breakpoints := emulationServers.debugStates[serverID].Breakpoints
+		index := slices.IndexFunc(breakpoints, func(currentBreakpoint breakpoint) bool {
+			return strconv.Itoa(currentBreakpoint.ID) == id
+		})
+		if index == -1 {
+			emulationServers.readWriteMutex.Unlock()
+			err := fmt.Errorf("invalid \"breakpoint_id\" parameter: breakpoint %s isn't found", id)
+			log.Printf("%s: %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+			return
+		}
+		emulationServers.debugStates[serverID].Breakpoints = slices.Delete(breakpoints, index, index+1)
+	}
+	emulationServers.readWriteMutex.Unlock()
+	gctx.JSON(http.StatusOK, []debugResponse{getDebugResponse(serverID)})
+}
+
+func getRequiredServerID(gctx *gin.Context) (serverID int, ok bool) {
+	var err error
+	var id string
+	if id, ok = gctx.GetQuery("server_id"); !ok {
+		err = fmt.Errorf("missed required \"server_id\" parameter")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+		return
+	}
+	serversData := getSettingsBuffer()
+	if serverID, err = strconv.Atoi(id); err != nil {
+		log.Printf("%s: invalid \"server_id\" parameter - %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{`Invalid "server_id" parameter`: err.Error()})
+		return serverID, false
+	}
+	if serverID > len(serversData)-1 || serverID < 0 {
+		log.Printf("Error on HTTP-request: \"server_id\" parameter must be in range [0:%d]", len(serversData))
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{`"server" parameter must be in range`: fmt.Sprintf("[0:%d]", len(serversData))})
+		return serverID, false
+	}
+	return
+}
+
+func sendEmulationCommand(gctx *gin.Context, serverID int, command emulationCommand) {
+	emulationServers.readWriteMutex.RLock()
+	emulationControlChannel := emulationServers.emulationControlChannels[serverID]
+	emulationServers.readWriteMutex.RUnlock()
+	command.appliedSignal = make(chan bool)
+	select {
+	case emulationControlChannel <- command:
+		<-command.appliedSignal
+	case <-time.After(time.Second):
+		err := fmt.Errorf("server couldn't process command (emulation isn't initialized)")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	gctx.JSON(http.StatusOK, []debugResponse{getDebugResponse(serverID)})
+}
+
+func getDebugResponse(serverID int) (response debugResponse) {
+	emulationServers.readWriteMutex.RLock()
+	defer emulationServers.readWriteMutex.RUnlock()
+	response = debugResponse{
+		ID:          serverID,
+		IsEmulating: emulationServers.serversData[serverID].IsEmulating,
+		Debug:       emulationServers.debugStates[serverID],
+	}
+	response.Debug.Breakpoints = slices.Clone(response.Debug.Breakpoints)
+	return
+}
+
+func getTag(gctx *gin.Context) {
+	tag, serverIDs, ok := getTagServers(gctx)
+	if !ok {
+		return
+	}
+	var response []tagValue
+	emulationServers.registersMutex.RLock()
+	emulationServers.readWriteMutex.RLock()
+	for _, currentID := range serverIDs {
+		response = append(response, readTagValue(currentID, emulationServers.servers[currentID], tag))
+	}
+	emulationServers.readWriteMutex.RUnlock()
+	emulationServers.registersMutex.RUnlock()
+	gctx.JSON(http.StatusOK, response)
+}
+
+func setTag(gctx *gin.Context) {
+	var err error
+	var value string
+	var ok bool
+	if value, ok = gctx.GetQuery("value"); !ok {
+		err = fmt.Errorf("missed required \"value\" parameter")
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusBadRequest, gin.H{errorHeader: err.Error()})
+		return
+	}
+	var tag registermap.Tag
+	var serverIDs []int
+	if tag, serverIDs, ok = getTagServers(gctx); !ok {
+		return
+	}
+	var registers []uint16
+	if registers, err = tag.Encode(value); err != nil {
+		err = fmt.Errorf("invalid \"value\" parameter: %s", err)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return
+	}
+	var response []tagValue
+	servers := make([]*ms.Server, len(serverIDs))
+	emulationServers.readWriteMutex.RLock()
+	for currentIndex, currentID := range serverIDs {
+		servers[currentIndex] = emulationServers.servers[currentID]
+	}
+	emulationServers.readWriteMutex.RUnlock()
+	emulationServers.registersMutex.Lock()
+	for currentIndex, currentID := range serverIDs {
+		if err = writeTagRegisters(servers[currentIndex], tag, registers); err != nil {
+			response = append(response, tagValue{ServerID: currentID, Tag: tag, Error: err.Error()})
+			continue
+		}
+		pinRegisters(servers[currentIndex])
+		log.Printf("Tag %s of %d server set to %s", tag.Name, currentID, value)
+		response = append(response, readTagValue(currentID, servers[currentIndex], tag))
+	}
+	emulationServers.registersMutex.Unlock()
+	gctx.JSON(http.StatusOK, response)
+}
+
+func getTagServers(gctx *gin.Context) (tag registermap.Tag, serverIDs []int, ok bool) {
+	var err error
+	if tag, ok = RegisterMap.Find(gctx.Param("name")); !ok {
+		err = fmt.Errorf("tag %s isn't found in register map", gctx.Param("name"))
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusNotFound, gin.H{errorHeader: err.Error()})
+		return
+	}
+	serversData := getSettingsBuffer()
+	if id, isSet := gctx.GetQuery("server_id"); isSet {
+		var serverID int
+		if serverID, err = strconv.Atoi(id); err != nil {
+			log.Printf("%s: invalid \"server_id\" parameter - %s", errorHeader, err)
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{`Invalid "server_id" parameter`: err.Error()})
+			return tag, nil, false
+		}
+		if serverID > len(serversData)-1 || serverID < 0 {
+			log.Printf("Error on HTTP-request: \"server_id\" parameter must be in range [0:%d]", len(serversData))
+			gctx.JSON(http.StatusUnprocessableEntity, gin.H{`"server" parameter must be in range`: fmt.Sprintf("[0:%d]", len(serversData))})
+			return tag, nil, false
+		}
+		if tag.Socket == "" || tag.Socket == serversData[serverID].RealSocket {
+			serverIDs = append(serverIDs, serverID)
+		}
+	} else {
+		for currentID, currentData := range serversData {
+			if tag.Socket == "" || tag.Socket == currentData.RealSocket {
+				serverIDs = append(serverIDs, currentID)
+			}
+		}
+	}
+	if len(serverIDs) == 0 {
+		err = fmt.Errorf("tag %s isn't served by requested servers", tag.Name)
+		log.Printf("%s: %s", errorHeader, err)
+		gctx.JSON(http.StatusUnprocessableEntity, gin.H{errorHeader: err.Error()})
+		return tag, nil, false
+	}
+	return
+}
+
 func getSettingsBuffer() []emulationServerSettings {
 	emulationServers.readWriteMutex.RLock()
 	serversData := make([]emulationServerSettings, len(emulationServers.serversData))
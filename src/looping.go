@@ -0,0 +1,100 @@
+package src
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	mS "github.com/Daniil-Kurganov/modbus-server"
+)
+
+type loopSegment struct {
+	IsEnabled bool   `json:"is_enabled"`
+	InTime    string `json:"in_time"`
+	OutTime   string `json:"out_time"`
+	InIndex   int    `json:"in_index"`
+	OutIndex  int    `json:"out_index"`
+	Counter   int    `json:"counter"`
+}
+
+func newLoopSegment(history structs.HistorySource, inTimeString, outTimeString string) (segment loopSegment, err error) {
+	if inTimeString == "" && outTimeString == "" {
+		return
+	}
+	var inTime, outTime time.Time
+	if inTime, err = conf.ParseLoopTime(inTimeString); err != nil {
+		return
+	}
+	if outTime, err = conf.ParseLoopTime(outTimeString); err != nil {
+		return
+	}
+	if segment.InIndex, segment.OutIndex, err = structs.SearchSegment(history, inTime, outTime); err != nil {
+		err = fmt.Errorf("error on searching loop segment: %s", err)
+		return
+	}
+	if inTime, err = history.GetTransactionTime(segment.InIndex); err != nil {
+		return
+	}
+	if outTime, err = history.GetTransactionTime(segment.OutIndex); err != nil {
+		return
+	}
+	segment.IsEnabled, segment.InTime, segment.OutTime = true, inTime.String(), outTime.String()
+	return
+}
+
+func loopStartIndex(server *mS.Server, serverID int, history structs.HistorySource, keyframes *structs.Keyframes, isRestarted bool) (startIndex int) {
+	emulationServers.readWriteMutex.RLock()
+	segment := emulationServers.serversData[serverID].Loop
+	isOneTimeEmulation := emulationServers.serversData[serverID].OneTimeEmulation
+	resetPolicy := emulationServers.serversData[serverID].ResetPolicy
+	emulationServers.readWriteMutex.RUnlock()
+	if segment.IsEnabled && !isOneTimeEmulation {
+		startIndex = segment.InIndex
+	}
+	if isRestarted {
+		applyResetPolicy(server, history, keyframes, resetPolicy, startIndex)
+	} else if startIndex > 0 {
+		log.Printf("Starting emulation from loop-in point (transaction %d)", startIndex)
+		restoreRegistersImage(server, history, keyframes, startIndex)
+	}
+	return
+}
+
+func nextTransactionIndex(server *mS.Server, serverID int, history structs.HistorySource, keyframes *structs.Keyframes, currentIndex int) int {
+	emulationServers.readWriteMutex.RLock()
+	segment := emulationServers.serversData[serverID].Loop
+	isOneTimeEmulation := emulationServers.serversData[serverID].OneTimeEmulation
+	emulationServers.readWriteMutex.RUnlock()
+	if !segment.IsEnabled || isOneTimeEmulation || currentIndex != segment.OutIndex {
+		return currentIndex + 1
+	}
+	emulationServers.readWriteMutex.Lock()
+	emulationServers.serversData[serverID].Loop.Counter++
+	segment = emulationServers.serversData[serverID].Loop
+	counter, inIndex, resetPolicy := segment.Counter, segment.InIndex, emulationServers.serversData[serverID].ResetPolicy
+	emulationServers.readWriteMutex.Unlock()
+	log.Printf("Loop-out point has been reached, starting loop %d from transaction %d", counter+1, inIndex)
+	applyResetPolicy(server, history, keyframes, resetPolicy, inIndex)
+	return inIndex
+}
+
+func applyResetPolicy(server *mS.Server, history structs.HistorySource, keyframes *structs.Keyframes, resetPolicy string, transactionIndex int) {
+	switch resetPolicy {
+	case conf.ResetPolicies.Zero:
+		emulationServers.registersMutex.Lock()
+		for _, currentSlave := range server.Slaves {
+			clear(currentSlave.Coils)
+			clear(currentSlave.DiscreteInputs)
+			clear(currentSlave.HoldingRegisters)
+			clear(currentSlave.InputRegisters)
+		}
+		pinRegisters(server)
+		emulationServers.registersMutex.Unlock()
+		log.Print("Registers have been reset to zero")
+	case conf.ResetPolicies.Snapshot:
+		restoreRegistersImage(server, history, keyframes, transactionIndex)
+	}
+}
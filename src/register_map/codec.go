@@ -0,0 +1,179 @@
+package registermap
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"modbus-emulator/conf"
+)
+
+func (t *Tag) Validate() (err error) {
+	if t.Name == "" {
+		return fmt.Errorf("empty tag name")
+	}
+	if !slices.Contains([]string{conf.ObjectTypes.Coils, conf.ObjectTypes.DI, conf.ObjectTypes.HR, conf.ObjectTypes.IR}, t.ObjectType) {
+		return fmt.Errorf("invalid object type of tag %s: %s", t.Name, t.ObjectType)
+	}
+	isBitObject := t.ObjectType == conf.ObjectTypes.Coils || t.ObjectType == conf.ObjectTypes.DI
+	switch t.Type {
+	case Types.Bool:
+		if !isBitObject {
+			return fmt.Errorf("invalid type of tag %s: %s isn't allowed for %s", t.Name, t.Type, t.ObjectType)
+		}
+	case Types.Uint16, Types.Int16, Types.Uint32, Types.Int32, Types.Float32, Types.Float64, Types.Bitfield, Types.String:
+		if isBitObject {
+			return fmt.Errorf("invalid type of tag %s: %s isn't allowed for %s", t.Name, t.Type, t.ObjectType)
+		}
+	default:
+		return fmt.Errorf("invalid type of tag %s: %s", t.Name, t.Type)
+	}
+	if t.WordOrder != "" && t.WordOrder != WordOrders.Big && t.WordOrder != WordOrders.Little {
+		return fmt.Errorf("invalid word order of tag %s: %s", t.Name, t.WordOrder)
+	}
+	if t.ByteOrder != "" && t.ByteOrder != ByteOrders.Big && t.ByteOrder != ByteOrders.Little {
+		return fmt.Errorf("invalid byte order of tag %s: %s", t.Name, t.ByteOrder)
+	}
+	if int(t.Address)+int(t.RegistersCount()) > math.MaxUint16+1 {
+		return fmt.Errorf("tag %s is out of address space", t.Name)
+	}
+	return
+}
+
+func (t *Tag) Decode(registers []uint16) (value any, err error) {
+	if len(registers) != int(t.RegistersCount()) {
+		err = fmt.Errorf("error on decoding tag %s: recieved %d registers instead of %d", t.Name, len(registers), t.RegistersCount())
+		return
+	}
+	switch t.Type {
+	case Types.Bool:
+		return registers[0] != 0, nil
+	case Types.String:
+		var text strings.Builder
+		for _, currentRegister := range t.orderWords(registers) {
+			currentRegister = t.orderBytes(currentRegister)
+			text.WriteByte(byte(currentRegister >> 8))
+			text.WriteByte(byte(currentRegister))
+		}
+		return strings.TrimRight(text.String(), "\x00"), nil
+	case Types.Bitfield:
+		return t.orderBytes(registers[0]), nil
+	}
+	var raw uint64
+	for _, currentRegister := range t.orderWords(registers) {
+		raw = raw<<16 | uint64(t.orderBytes(currentRegister))
+	}
+	var number float64
+	switch t.Type {
+	case Types.Uint16, Types.Uint32:
+		number = float64(raw)
+	case Types.Int16:
+		number = float64(int16(raw))
+	case Types.Int32:
+		number = float64(int32(raw))
+	case Types.Float32:
+		number = float64(math.Float32frombits(uint32(raw)))
+	case Types.Float64:
+		number = math.Float64frombits(raw)
+	}
+	return t.EngineeringValue(number), nil
+}
+
+func (t *Tag) Encode(value string) (registers []uint16, err error) {
+	switch t.Type {
+	case Types.Bool:
+		var flag bool
+		if flag, err = strconv.ParseBool(value); err != nil {
+			err = fmt.Errorf("error on encoding tag %s: invalid boolean value %s", t.Name, value)
+			return
+		}
+		registers = []uint16{0}
+		if flag {
+			registers[0] = 1
+		}
+		return
+	case Types.String:
+		if len(value) > 2*int(t.RegistersCount()) {
+			err = fmt.Errorf("error on encoding tag %s: string is longer than %d characters", t.Name, 2*t.RegistersCount())
+			return
+		}
+		text := []byte(value)
+		text = append(text, make([]byte, 2*int(t.RegistersCount())-len(text))...)
+		for currentIndex := 0; currentIndex < len(text); currentIndex += 2 {
+			registers = append(registers, t.orderBytes(uint16(text[currentIndex])<<8|uint16(text[currentIndex+1])))
+		}
+		return
+	case Types.Bitfield:
+		var bitfield uint64
+		if bitfield, err = strconv.ParseUint(value, 0, 16); err != nil {
+			err = fmt.Errorf("error on encoding tag %s: invalid bitfield value %s", t.Name, value)
+			return
+		}
+		return []uint16{t.orderBytes(uint16(bitfield))}, nil
+	}
+	var number float64
+	if number, err = strconv.ParseFloat(value, 64); err != nil {
+		err = fmt.Errorf("error on encoding tag %s: invalid numeric value %s", t.Name, value)
+		return
+	}
+	number = t.RawValue(number)
+	var raw uint64
+	switch t.Type {
+	case Types.Uint16, Types.Int16, Types.Uint32, Types.Int32:
+		limits := map[string][2]float64{
+			Types.Uint16: {0, math.MaxUint16},
+			Types.Int16:  {math.MinInt16, math.MaxInt16},
+			Types.Uint32: {0, math.MaxUint32},
+			Types.Int32:  {math.MinInt32, math.MaxInt32},
+		}[t.Type]
+		number = math.Round(number)
+		if number < limits[0] || number > limits[1] {
+			err = fmt.Errorf("error on encoding tag %s: raw value %v is out of %s range", t.Name, number, t.Type)
+			return
+		}
+		raw = uint64(int64(number))
+	case Types.Float32:
+		raw = uint64(math.Float32bits(float32(number)))
+	case Types.Float64:
+		raw = math.Float64bits(number)
+	}
+	registers = make([]uint16, t.RegistersCount())
+	for currentIndex := len(registers) - 1; currentIndex >= 0; currentIndex-- {
+		registers[currentIndex] = t.orderBytes(uint16(raw))
+		raw >>= 16
+	}
+	return t.orderWords(registers), nil
+}
+
+func (t *Tag) RawValue(engineeringValue float64) float64 {
+	scale := t.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	return (engineeringValue - t.Offset) / scale
+}
+
+func (t *Tag) EngineeringValue(raw float64) float64 {
+	scale := t.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	return raw*scale + t.Offset
+}
+
+func (t *Tag) orderWords(registers []uint16) (ordered []uint16) {
+	ordered = slices.Clone(registers)
+	if t.WordOrder == WordOrders.Little && t.Type != Types.String {
+		slices.Reverse(ordered)
+	}
+	return
+}
+
+func (t *Tag) orderBytes(register uint16) uint16 {
+	if t.ByteOrder == ByteOrders.Little {
+		return register<<8 | register>>8
+	}
+	return register
+}
@@ -0,0 +1,325 @@
+package registermap
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+)
+
+const (
+	minimalStringLength      = 2
+	minimalCounterValues     = 3
+	bitfieldChangesShare     = 0.8
+	scaledValueSpreadShare   = 0.2
+	minimalScaledValue       = 100
+	minimalFloatExponent     = 127 - 20
+	maximalFloatExponent     = 127 + 30
+	signedValuesBorderOffset = 0x1000
+)
+
+type (
+	objectKey struct {
+		socket     string
+		slave      uint8
+		objectType string
+	}
+	objectSamples struct {
+		series map[uint16][]uint16
+		pairs  map[uint16][][2]uint16
+	}
+)
+
+func Infer(history map[string]structs.ServerHistory) (registerMap RegisterMap, err error) {
+	var samples map[objectKey]*objectSamples
+	if samples, err = collectSamples(history); err != nil {
+		return
+	}
+	for currentKey, currentSamples := range samples {
+		registerMap.Tags = append(registerMap.Tags, inferObjectTags(currentKey, currentSamples)...)
+	}
+	registerMap.Sort()
+	return
+}
+
+func collectSamples(history map[string]structs.ServerHistory) (samples map[objectKey]*objectSamples, err error) {
+	samples = make(map[objectKey]*objectSamples)
+	for currentServePath, currentHistory := range history {
+		for _, currentEvent := range currentHistory.Transactions {
+			if currentEvent.Handshake.TransactionErrorCheck() {
+				continue
+			}
+			var currentData structs.EmulationData
+			if currentData, err = currentEvent.Handshake.Marshal(); err != nil {
+				err = fmt.Errorf("error on marshaling transaction %s of %s: %s", currentEvent.Header.TransactionID, currentServePath, err)
+				return
+			}
+			currentKey := objectKey{currentServePath, currentEvent.Header.SlaveID, structs.FunctionObjectType(currentData.FunctionID)}
+			if currentKey.objectType == "" {
+				continue
+			}
+			currentSamples, ok := samples[currentKey]
+			if !ok {
+				currentSamples = &objectSamples{series: make(map[uint16][]uint16), pairs: make(map[uint16][][2]uint16)}
+				samples[currentKey] = currentSamples
+			}
+			quantity := min(int(currentData.Quantity), len(currentData.Payload), math.MaxUint16-int(currentData.Address)+1)
+			for currentIndex := 0; currentIndex < quantity; currentIndex++ {
+				currentAddress := currentData.Address + uint16(currentIndex)
+				currentSamples.series[currentAddress] = append(currentSamples.series[currentAddress], currentData.Payload[currentIndex])
+				if currentIndex+1 < quantity {
+					currentSamples.pairs[currentAddress] = append(currentSamples.pairs[currentAddress],
+						[2]uint16{currentData.Payload[currentIndex], currentData.Payload[currentIndex+1]})
+				}
+			}
+		}
+	}
+	return
+}
+
+func inferObjectTags(key objectKey, samples *objectSamples) (tags []Tag) {
+	addresses := make([]uint16, 0, len(samples.series))
+	for currentAddress := range samples.series {
+		addresses = append(addresses, currentAddress)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i] < addresses[j] })
+	newTag := func(address uint16, tagType string) Tag {
+		return Tag{Socket: key.socket, Slave: key.slave, ObjectType: key.objectType, Address: address, Type: tagType}
+	}
+	if key.objectType == conf.ObjectTypes.Coils || key.objectType == conf.ObjectTypes.DI {
+		for _, currentAddress := range addresses {
+			tags = append(tags, nameTag(newTag(currentAddress, Types.Bool)))
+		}
+		return
+	}
+	consumed := make(map[uint16]bool)
+	for _, currentAddress := range addresses {
+		if consumed[currentAddress] {
+			continue
+		}
+		if length := stringLength(samples, currentAddress); length >= minimalStringLength {
+			currentTag := newTag(currentAddress, Types.String)
+			currentTag.Length = length
+			tags = append(tags, nameTag(currentTag))
+			for currentOffset := uint16(0); currentOffset < length; currentOffset++ {
+				consumed[currentAddress+currentOffset] = true
+			}
+			continue
+		}
+		if currentAddress < math.MaxUint16 && !consumed[currentAddress+1] {
+			if tagType, wordOrder, isCounter, ok := inferPair(samples.pairs[currentAddress]); ok {
+				currentTag := newTag(currentAddress, tagType)
+				currentTag.WordOrder, currentTag.Counter = wordOrder, isCounter
+				tags = append(tags, nameTag(currentTag))
+				consumed[currentAddress], consumed[currentAddress+1] = true, true
+				continue
+			}
+		}
+		currentTag := newTag(currentAddress, Types.Uint16)
+		var isScaled bool
+		currentTag.Type, isScaled, currentTag.Counter = inferSingle(samples.series[currentAddress])
+		currentTag = nameTag(currentTag)
+		if isScaled {
+			currentTag.Name = fmt.Sprintf("%s_scaled", currentTag.Name)
+		}
+		tags = append(tags, currentTag)
+		consumed[currentAddress] = true
+	}
+	return
+}
+
+func nameTag(tag Tag) Tag {
+	suffix := tag.Type
+	if tag.Counter {
+		suffix = "counter"
+	}
+	tag.Name = fmt.Sprintf("slave%d_%s%d_%s", tag.Slave, strings.ToLower(tag.ObjectType), tag.Address, suffix)
+	return tag
+}
+
+func stringLength(samples *objectSamples, address uint16) (length uint16) {
+	printableCharacters, isPadded := 0, false
+	for currentAddress := int(address); currentAddress <= math.MaxUint16 && !isPadded; currentAddress++ {
+		currentSeries, ok := samples.series[uint16(currentAddress)]
+		if !ok || !constantSeries(currentSeries) {
+			break
+		}
+		currentPrintable := 0
+		for _, currentByte := range []byte{byte(currentSeries[0] >> 8), byte(currentSeries[0])} {
+			switch {
+			case currentByte == 0:
+				isPadded = true
+			case currentByte >= 0x20 && currentByte <= 0x7e && !isPadded:
+				currentPrintable++
+			default:
+				currentPrintable = -1
+			}
+			if currentPrintable < 0 {
+				break
+			}
+		}
+		if currentPrintable <= 0 {
+			break
+		}
+		printableCharacters += currentPrintable
+		length++
+	}
+	if printableCharacters < 2*minimalStringLength-1 {
+		length = 0
+	}
+	return
+}
+
+func constantSeries(series []uint16) bool {
+	for _, currentValue := range series {
+		if currentValue != series[0] {
+			return false
+		}
+	}
+	return true
+}
+
+func inferPair(pairs [][2]uint16) (tagType, wordOrder string, isCounter, ok bool) {
+	if len(pairs) < 2 {
+		return
+	}
+	for _, currentOrder := range []string{WordOrders.Big, WordOrders.Little} {
+		if floatPairs(pairs, currentOrder) {
+			return Types.Float32, currentOrder, false, true
+		}
+	}
+	for _, currentOrder := range []string{WordOrders.Big, WordOrders.Little} {
+		if isCarried, isSigned, isMonotonic := integerPairs(pairs, currentOrder); isCarried {
+			tagType = Types.Uint32
+			if isSigned {
+				tagType = Types.Int32
+			}
+			return tagType, currentOrder, isMonotonic, true
+		}
+	}
+	return
+}
+
+func PairValue(pair [2]uint16, wordOrder string) uint32 {
+	if wordOrder == WordOrders.Little {
+		return uint32(pair[1])<<16 | uint32(pair[0])
+	}
+	return uint32(pair[0])<<16 | uint32(pair[1])
+}
+
+func floatPairs(pairs [][2]uint16, wordOrder string) bool {
+	distinctValues := make(map[uint32]bool)
+	hasFraction := false
+	for _, currentPair := range pairs {
+		currentBits := PairValue(currentPair, wordOrder)
+		distinctValues[currentBits] = true
+		if currentBits&0x7fffffff == 0 {
+			continue
+		}
+		exponent := (currentBits >> 23) & 0xff
+		if exponent < minimalFloatExponent || exponent > maximalFloatExponent {
+			return false
+		}
+		currentValue := float64(math.Float32frombits(currentBits))
+		if currentValue != math.Trunc(currentValue) {
+			hasFraction = true
+		}
+	}
+	return len(distinctValues) > 1 && hasFraction
+}
+
+func integerPairs(pairs [][2]uint16, wordOrder string) (isCarried, isSigned, isMonotonic bool) {
+	for currentIndex, currentPair := range pairs {
+		currentValue := PairValue(currentPair, wordOrder)
+		if currentValue>>31 == 1 {
+			isSigned = true
+		}
+		if currentIndex == 0 {
+			continue
+		}
+		previousValue := PairValue(pairs[currentIndex-1], wordOrder)
+		previousHigh, previousLow := uint16(previousValue>>16), uint16(previousValue)
+		currentHigh, currentLow := uint16(currentValue>>16), uint16(currentValue)
+		switch {
+		case currentHigh == previousHigh:
+		case currentHigh == previousHigh+1 && currentLow < previousLow, currentHigh == previousHigh-1 && currentLow > previousLow:
+			isCarried = true
+		default:
+			return false, false, false
+		}
+	}
+	isMonotonic = true
+	for currentIndex := 1; currentIndex < len(pairs); currentIndex++ {
+		previousValue, currentValue := PairValue(pairs[currentIndex-1], wordOrder), PairValue(pairs[currentIndex], wordOrder)
+		if isSigned && int32(currentValue) < int32(previousValue) || !isSigned && currentValue < previousValue {
+			isMonotonic = false
+			break
+		}
+	}
+	return
+}
+
+func inferSingle(series []uint16) (tagType string, isScaled, isCounter bool) {
+	tagType = Types.Uint16
+	distinctValues := make(map[uint16]bool)
+	minimalValue, maximalValue, sum := uint16(math.MaxUint16), uint16(0), 0.0
+	hasNegative, hasPositive := false, false
+	for _, currentValue := range series {
+		distinctValues[currentValue] = true
+		minimalValue, maximalValue = min(minimalValue, currentValue), max(maximalValue, currentValue)
+		sum += float64(currentValue)
+		if currentValue >= math.MaxUint16-signedValuesBorderOffset {
+			hasNegative = true
+		} else if currentValue < signedValuesBorderOffset {
+			hasPositive = true
+		}
+	}
+	if len(distinctValues) < 2 {
+		return
+	}
+	if len(distinctValues) >= minimalCounterValues && counterSeries(series) {
+		return tagType, false, true
+	}
+	if bitfieldSeries(series) {
+		return Types.Bitfield, false, false
+	}
+	if hasNegative && hasPositive {
+		return Types.Int16, false, false
+	}
+	mean := sum / float64(len(series))
+	isScaled = len(distinctValues) >= minimalCounterValues && minimalValue >= minimalScaledValue &&
+		float64(maximalValue-minimalValue) <= scaledValueSpreadShare*mean
+	return
+}
+
+func counterSeries(series []uint16) bool {
+	increases := 0
+	for currentIndex := 1; currentIndex < len(series); currentIndex++ {
+		switch difference := series[currentIndex] - series[currentIndex-1]; {
+		case difference == 0:
+		case difference < math.MaxInt16:
+			increases++
+		default:
+			return false
+		}
+	}
+	return increases >= minimalCounterValues-1
+}
+
+func bitfieldSeries(series []uint16) bool {
+	changes, singleBitChanges := 0, 0
+	for currentIndex := 1; currentIndex < len(series); currentIndex++ {
+		if series[currentIndex] == series[currentIndex-1] {
+			continue
+		}
+		changes++
+		if bits.OnesCount16(series[currentIndex]^series[currentIndex-1]) == 1 {
+			singleBitChanges++
+		}
+	}
+	return changes >= 2 && float64(singleBitChanges) >= bitfieldChangesShare*float64(changes)
+}
@@ -0,0 +1,147 @@
+package registermap
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	Types = struct {
+		Bool     string
+		Uint16   string
+		Int16    string
+		Uint32   string
+		Int32    string
+		Float32  string
+		Float64  string
+		Bitfield string
+		String   string
+	}{
+		Bool:     "bool",
+		Uint16:   "uint16",
+		Int16:    "int16",
+		Uint32:   "uint32",
+		Int32:    "int32",
+		Float32:  "float32",
+		Float64:  "float64",
+		Bitfield: "bitfield",
+		String:   "string",
+	}
+	WordOrders = struct {
+		Big    string
+		Little string
+	}{
+		Big:    "big",
+		Little: "little",
+	}
+	ByteOrders = struct {
+		Big    string
+		Little string
+	}{
+		Big:    "big",
+		Little: "little",
+	}
+)
+
+type (
+	Tag struct {
+		Name       string  `toml:"Name" json:"name"`
+		Socket     string  `toml:"Socket" json:"socket"`
+		Slave      uint8   `toml:"Slave" json:"slave"`
+		ObjectType string  `toml:"ObjectType" json:"object_type"`
+		Address    uint16  `toml:"Address" json:"address"`
+		Type       string  `toml:"Type" json:"type"`
+		ByteOrder  string  `toml:"ByteOrder,omitempty" json:"byte_order,omitempty"`
+		WordOrder  string  `toml:"WordOrder,omitempty" json:"word_order,omitempty"`
+		Length     uint16  `toml:"Length,omitempty" json:"length,omitempty"`
+		Scale      float64 `toml:"Scale,omitempty" json:"scale,omitempty"`
+		Offset     float64 `toml:"Offset,omitempty" json:"offset,omitempty"`
+		Unit       string  `toml:"Unit,omitempty" json:"unit,omitempty"`
+		Counter    bool    `toml:"Counter,omitempty" json:"counter,omitempty"`
+	}
+	RegisterMap struct {
+		Tags []Tag `toml:"Tag"`
+	}
+)
+
+func (t *Tag) RegistersCount() uint16 {
+	switch t.Type {
+	case Types.Uint32, Types.Int32, Types.Float32:
+		return 2
+	case Types.Float64:
+		return 4
+	case Types.String:
+		return max(t.Length, 1)
+	}
+	return 1
+}
+
+func (rM *RegisterMap) Sort() {
+	sort.SliceStable(rM.Tags, func(i, j int) bool {
+		first, second := rM.Tags[i], rM.Tags[j]
+		if first.Socket != second.Socket {
+			return first.Socket < second.Socket
+		}
+		if first.Slave != second.Slave {
+			return first.Slave < second.Slave
+		}
+		if first.ObjectType != second.ObjectType {
+			return first.ObjectType < second.ObjectType
+		}
+		return first.Address < second.Address
+	})
+}
+
+func Load(path string) (registerMap RegisterMap, err error) {
+	if _, err = toml.DecodeFile(path, &registerMap); err != nil {
+		err = fmt.Errorf("error on reading register map: %s", err)
+		return
+	}
+	names := make(map[string]bool)
+	for currentIndex, currentTag := range registerMap.Tags {
+		if err = currentTag.Validate(); err != nil {
+			err = fmt.Errorf("error on tag %d of register map: %s", currentIndex, err)
+			return
+		}
+		if names[currentTag.Name] {
+			err = fmt.Errorf("error on tag %d of register map: duplicated name %s", currentIndex, currentTag.Name)
+			return
+		}
+		names[currentTag.Name] = true
+	}
+	return
+}
+
+func (rM *RegisterMap) Find(name string) (tag Tag, ok bool) {
+	for _, currentTag := range rM.Tags {
+		if currentTag.Name == name {
+			return currentTag, true
+		}
+	}
+	return
+}
+
+func (rM *RegisterMap) ServerTags(servePath string) (tags []Tag) {
+	for _, currentTag := range rM.Tags {
+		if currentTag.Socket == "" || currentTag.Socket == servePath {
+			tags = append(tags, currentTag)
+		}
+	}
+	return
+}
+
+func Save(path string, registerMap RegisterMap) (err error) {
+	var file *os.File
+	if file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666); err != nil {
+		err = fmt.Errorf("error on creating register map file: %s", err)
+		return
+	}
+	defer file.Close()
+	if err = toml.NewEncoder(file).Encode(registerMap); err != nil {
+		err = fmt.Errorf("error on writing register map: %s", err)
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package src
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"modbus-emulator/conf"
+	registermap "modbus-emulator/src/register_map"
+
+	mS "github.com/Daniil-Kurganov/modbus-server"
+)
+
+type tagValue struct {
+	ServerID int `json:"server_id"`
+	registermap.Tag
+	Value     any      `json:"value"`
+	Registers []uint16 `json:"registers"`
+	Error     string   `json:"error,omitempty"`
+}
+
+var RegisterMap registermap.RegisterMap
+
+func readTagRegisters(server *mS.Server, tag registermap.Tag) (registers []uint16, err error) {
+	slave, ok := server.Slaves[tag.Slave]
+	if !ok {
+		err = fmt.Errorf("slave %d isn't initialized", tag.Slave)
+		return
+	}
+	leftBorder, rightBorder := int(tag.Address), int(tag.Address)+int(tag.RegistersCount())
+	switch tag.ObjectType {
+	case conf.ObjectTypes.Coils:
+		registers = []uint16{uint16(slave.Coils[tag.Address])}
+	case conf.ObjectTypes.DI:
+		registers = []uint16{uint16(slave.DiscreteInputs[tag.Address])}
+	case conf.ObjectTypes.HR:
+		registers = slices.Clone(slave.HoldingRegisters[leftBorder:rightBorder])
+	case conf.ObjectTypes.IR:
+		registers = slices.Clone(slave.InputRegisters[leftBorder:rightBorder])
+	default:
+		err = fmt.Errorf("invalid object type: %s", tag.ObjectType)
+	}
+	return
+}
+
+func writeTagRegisters(server *mS.Server, tag registermap.Tag, registers []uint16) (err error) {
+	slave, ok := server.Slaves[tag.Slave]
+	if !ok {
+		return fmt.Errorf("slave %d isn't initialized", tag.Slave)
+	}
+	switch tag.ObjectType {
+	case conf.ObjectTypes.Coils:
+		slave.Coils[tag.Address] = byte(registers[0])
+	case conf.ObjectTypes.DI:
+		slave.DiscreteInputs[tag.Address] = byte(registers[0])
+	case conf.ObjectTypes.HR:
+		copy(slave.HoldingRegisters[tag.Address:], registers)
+	case conf.ObjectTypes.IR:
+		copy(slave.InputRegisters[tag.Address:], registers)
+	default:
+		err = fmt.Errorf("invalid object type: %s", tag.ObjectType)
+	}
+	return
+}
+
+func readTagValue(serverID int, server *mS.Server, tag registermap.Tag) (value tagValue) {
+	value = tagValue{ServerID: serverID, Tag: tag}
+	var err error
+	if value.Registers, err = readTagRegisters(server, tag); err == nil {
+		value.Value, err = tag.Decode(value.Registers)
+	}
+	if err != nil {
+		value.Error = err.Error()
+	}
+	return
+}
+
+func logTagValues(server *mS.Server, servePath string, slaveID uint8, objectType string, leftBorder, rightBorder int) {
+	var tagsLog strings.Builder
+	for _, currentTag := range RegisterMap.ServerTags(servePath) {
+		if currentTag.Slave != slaveID || currentTag.ObjectType != objectType ||
+			int(currentTag.Address) >= rightBorder || int(currentTag.Address)+int(currentTag.RegistersCount()) <= leftBorder {
+			continue
+		}
+		currentValue := readTagValue(0, server, currentTag)
+		if currentValue.Error != "" {
+			tagsLog.WriteString(fmt.Sprintf("\n %s: %s", currentTag.Name, currentValue.Error))
+			continue
+		}
+		tagsLog.WriteString(fmt.Sprintf("\n %s = %v %s", currentTag.Name, currentValue.Value, currentTag.Unit))
+	}
+	if tagsLog.Len() > 0 {
+		log.Printf(" Tags:%s", tagsLog.String())
+	}
+}
@@ -0,0 +1,415 @@
+package trafficanalysis
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+const (
+	idleGapMinimum    = time.Second
+	idleGapFactor     = 10
+	idleGapsLimit     = 20
+	chartWidth        = 640
+	chartBarHeight    = 22
+	chartLabelsWidth  = 160
+	chartCountsMargin = 60
+)
+
+var (
+	latencyPercentiles     = []float64{50, 90, 95, 99, 100}
+	latencyHistogramBounds = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, math.Inf(1)}
+)
+
+type (
+	FunctionCount struct {
+		Function uint16 `json:"function"`
+		Count    int    `json:"count"`
+	}
+	ExceptionCount struct {
+		Code  uint16 `json:"code"`
+		Count int    `json:"count"`
+	}
+	LatencyPercentile struct {
+		Percentile   float64 `json:"percentile"`
+		Milliseconds float64 `json:"milliseconds"`
+	}
+	HistogramBucket struct {
+		UpperMilliseconds float64 `json:"upper_milliseconds"`
+		Count             int     `json:"count"`
+	}
+	IdleGap struct {
+		Start   time.Time `json:"start"`
+		End     time.Time `json:"end"`
+		Seconds float64   `json:"seconds"`
+	}
+	SlaveAnalysis struct {
+		Slave                  uint8            `json:"slave"`
+		Transactions           int              `json:"transactions"`
+		Functions              []FunctionCount  `json:"functions"`
+		AddressRanges          []AddressRange   `json:"address_ranges"`
+		PollCyclePeriodSeconds float64          `json:"poll_cycle_period_seconds"`
+		Exceptions             []ExceptionCount `json:"exceptions"`
+	}
+	DeviceAnalysis struct {
+		Socket             string              `json:"socket"`
+		DumpSocket         string              `json:"dump_socket"`
+		Protocol           string              `json:"protocol"`
+		Transactions       int                 `json:"transactions"`
+		StartTime          time.Time           `json:"start_time"`
+		EndTime            time.Time           `json:"end_time"`
+		Functions          []FunctionCount     `json:"functions"`
+		Exceptions         int                 `json:"exceptions"`
+		Slaves             []SlaveAnalysis     `json:"slaves"`
+		LatencyPercentiles []LatencyPercentile `json:"latency_percentiles"`
+		LatencyHistogram   []HistogramBucket   `json:"latency_histogram"`
+		IdleGaps           []IdleGap           `json:"idle_gaps"`
+	}
+	AnalysisReport struct {
+		Dump    string           `json:"dump"`
+		Devices []DeviceAnalysis `json:"devices"`
+	}
+	chartBar struct {
+		Label string
+		Count int
+		Width float64
+		Y     int
+	}
+)
+
+func AnalyzeDump() (err error) {
+	var history map[string]structs.ServerHistory
+	if history, err = ParseDump(); err != nil {
+		return
+	}
+	var report AnalysisReport
+	if report, err = AnalyzeHistory(history); err != nil {
+		return
+	}
+	report.Dump = conf.DumpFilePath
+	return writeReport(report, map[string]func(io.Writer) error{
+		conf.ReportFormats.Text:     report.WriteMarkdown,
+		conf.ReportFormats.Markdown: report.WriteMarkdown,
+		conf.ReportFormats.HTML:     report.WriteHTML,
+	})
+}
+
+func AnalyzeHistory(history map[string]structs.ServerHistory) (report AnalysisReport, err error) {
+	servePaths := maps.Keys(history)
+	sort.Strings(servePaths)
+	for _, currentServePath := range servePaths {
+		var currentDevice DeviceAnalysis
+		if currentDevice, err = analyzeDevice(currentServePath, history[currentServePath]); err != nil {
+			return
+		}
+		report.Devices = append(report.Devices, currentDevice)
+	}
+	return
+}
+
+func analyzeDevice(servePath string, history structs.ServerHistory) (device DeviceAnalysis, err error) {
+	device = DeviceAnalysis{
+		Socket:       servePath,
+		Protocol:     conf.Sockets[servePath].Protocol,
+		Transactions: len(history.Transactions),
+		IdleGaps:     []IdleGap{},
+	}
+	if socketData, ok := conf.Sockets[servePath]; ok {
+		device.DumpSocket = fmt.Sprintf("%s:%s", socketData.HostAddress, socketData.PortAddress)
+	}
+	if len(history.Transactions) == 0 {
+		return
+	}
+	device.StartTime = history.Transactions[0].TransactionTime
+	device.EndTime = history.Transactions[len(history.Transactions)-1].TransactionTime
+	deviceFunctions := make(map[uint16]int)
+	slavesFunctions := make(map[uint8]map[uint16]int)
+	slavesExceptions := make(map[uint8]map[uint16]int)
+	slavesAddresses := make(map[uint8]map[registerKey]bool)
+	slavesPolls := make(map[uint8]map[pollKey][]time.Time)
+	var latencies, intervals []float64
+	for currentIndex, currentEvent := range history.Transactions {
+		var currentData structs.EmulationData
+		if currentData, err = currentEvent.Handshake.Marshal(); err != nil {
+			err = fmt.Errorf("error on marshaling transaction %s of %s: %s", currentEvent.Header.TransactionID, servePath, err)
+			return
+		}
+		currentSlave := currentEvent.Header.SlaveID
+		if _, ok := slavesFunctions[currentSlave]; !ok {
+			slavesFunctions[currentSlave] = make(map[uint16]int)
+			slavesExceptions[currentSlave] = make(map[uint16]int)
+			slavesAddresses[currentSlave] = make(map[registerKey]bool)
+			slavesPolls[currentSlave] = make(map[pollKey][]time.Time)
+		}
+		currentFunction := currentData.FunctionID &^ 0x80
+		deviceFunctions[currentFunction]++
+		slavesFunctions[currentSlave][currentFunction]++
+		currentPollKey := pollKey{servePath, currentSlave, currentFunction, currentData.Address, currentData.Quantity}
+		slavesPolls[currentSlave][currentPollKey] = append(slavesPolls[currentSlave][currentPollKey], currentEvent.TransactionTime)
+		if currentEvent.Handshake.TransactionErrorCheck() {
+			device.Exceptions++
+			slavesExceptions[currentSlave][currentEvent.Handshake.GetExceptionCode()]++
+		} else {
+			currentObjectType := structs.FunctionObjectType(currentFunction)
+			for currentAddress := int(currentData.Address); currentAddress < int(currentData.Address)+int(currentData.Quantity) && currentAddress <= math.MaxUint16; currentAddress++ {
+				slavesAddresses[currentSlave][registerKey{servePath, currentSlave, currentObjectType, uint16(currentAddress)}] = true
+			}
+		}
+		if !currentEvent.RequestTime.IsZero() {
+			latencies = append(latencies, float64(currentEvent.TransactionTime.Sub(currentEvent.RequestTime))/float64(time.Millisecond))
+		}
+		if currentIndex != 0 {
+			intervals = append(intervals, currentEvent.TransactionTime.Sub(history.Transactions[currentIndex-1].TransactionTime).Seconds())
+		}
+	}
+	device.Functions = functionCounts(deviceFunctions)
+	for _, currentSlave := range history.Slaves {
+		if _, ok := slavesFunctions[currentSlave]; !ok {
+			continue
+		}
+		currentSlaveAnalysis := SlaveAnalysis{
+			Slave:                  currentSlave,
+			Functions:              functionCounts(slavesFunctions[currentSlave]),
+			AddressRanges:          mergeAddressRanges(maps.Keys(slavesAddresses[currentSlave])),
+			PollCyclePeriodSeconds: pollCyclePeriod(slavesPolls[currentSlave]),
+			Exceptions:             []ExceptionCount{},
+		}
+		for _, currentFunction := range currentSlaveAnalysis.Functions {
+			currentSlaveAnalysis.Transactions += currentFunction.Count
+		}
+		for currentCode, currentCount := range slavesExceptions[currentSlave] {
+			currentSlaveAnalysis.Exceptions = append(currentSlaveAnalysis.Exceptions, ExceptionCount{currentCode, currentCount})
+		}
+		sort.Slice(currentSlaveAnalysis.Exceptions, func(i, j int) bool {
+			return currentSlaveAnalysis.Exceptions[i].Code < currentSlaveAnalysis.Exceptions[j].Code
+		})
+		device.Slaves = append(device.Slaves, currentSlaveAnalysis)
+	}
+	device.LatencyPercentiles, device.LatencyHistogram = latencyStatistics(latencies)
+	if len(intervals) != 0 {
+		sortedIntervals := append([]float64(nil), intervals...)
+		sort.Float64s(sortedIntervals)
+		idleThreshold := math.Max(idleGapMinimum.Seconds(), idleGapFactor*sortedIntervals[len(sortedIntervals)/2])
+		for currentIndex, currentInterval := range intervals {
+			if currentInterval >= idleThreshold {
+				device.IdleGaps = append(device.IdleGaps, IdleGap{
+					Start:   history.Transactions[currentIndex].TransactionTime,
+					End:     history.Transactions[currentIndex+1].TransactionTime,
+					Seconds: currentInterval,
+				})
+			}
+		}
+		sort.SliceStable(device.IdleGaps, func(i, j int) bool { return device.IdleGaps[i].Seconds > device.IdleGaps[j].Seconds })
+		device.IdleGaps = device.IdleGaps[:min(len(device.IdleGaps), idleGapsLimit)]
+	}
+	return
+}
+
+func functionCounts(functions map[uint16]int) (counts []FunctionCount) {
+	for currentFunction, currentCount := range functions {
+		counts = append(counts, FunctionCount{currentFunction, currentCount})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Function < counts[j].Function })
+	return
+}
+
+func pollCyclePeriod(polls map[pollKey][]time.Time) float64 {
+	var mostPolledTimes []time.Time
+	for _, currentTimes := range polls {
+		if len(currentTimes) > len(mostPolledTimes) {
+			mostPolledTimes = currentTimes
+		}
+	}
+	if len(mostPolledTimes) < 2 {
+		return 0
+	}
+	return meanPeriod(mostPolledTimes)
+}
+
+func latencyStatistics(latencies []float64) (percentiles []LatencyPercentile, histogram []HistogramBucket) {
+	percentiles, histogram = []LatencyPercentile{}, []HistogramBucket{}
+	if len(latencies) == 0 {
+		return
+	}
+	sortedLatencies := append([]float64(nil), latencies...)
+	sort.Float64s(sortedLatencies)
+	for _, currentPercentile := range latencyPercentiles {
+		currentIndex := int(math.Ceil(currentPercentile/100*float64(len(sortedLatencies)))) - 1
+		percentiles = append(percentiles, LatencyPercentile{currentPercentile, sortedLatencies[max(currentIndex, 0)]})
+	}
+	currentLatency := 0
+	for _, currentBound := range latencyHistogramBounds {
+		currentBucket := HistogramBucket{UpperMilliseconds: currentBound}
+		for ; currentLatency < len(sortedLatencies) && sortedLatencies[currentLatency] <= currentBound; currentLatency++ {
+			currentBucket.Count++
+		}
+		histogram = append(histogram, currentBucket)
+	}
+	return
+}
+
+func (r *AnalysisReport) WriteMarkdown(writer io.Writer) (err error) {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Dump analysis: %s\n", r.Dump)
+	for _, currentDevice := range r.Devices {
+		fmt.Fprintf(&builder, "\n## %s (dump socket %s, %s)\n\n", currentDevice.Socket, currentDevice.DumpSocket, currentDevice.Protocol)
+		fmt.Fprintf(&builder, "- Transactions: %d\n", currentDevice.Transactions)
+		fmt.Fprintf(&builder, "- Time range: %s — %s\n", formatReportTime(currentDevice.StartTime), formatReportTime(currentDevice.EndTime))
+		fmt.Fprintf(&builder, "- Exceptions: %d\n", currentDevice.Exceptions)
+		builder.WriteString("\n### Function codes\n\n| Function | Count |\n| --- | --- |\n")
+		for _, currentFunction := range currentDevice.Functions {
+			fmt.Fprintf(&builder, "| %d | %d |\n", currentFunction.Function, currentFunction.Count)
+		}
+		builder.WriteString("\n### Slaves\n\n| Slave | Transactions | Poll cycle, s | Address ranges | Exceptions |\n| --- | --- | --- | --- | --- |\n")
+		for _, currentSlave := range currentDevice.Slaves {
+			fmt.Fprintf(&builder, "| %d | %d | %.3f | %s | %s |\n", currentSlave.Slave, currentSlave.Transactions,
+				currentSlave.PollCyclePeriodSeconds, formatAddressRanges(currentSlave.AddressRanges), formatExceptionCounts(currentSlave.Exceptions))
+		}
+		builder.WriteString("\n### Latency\n\n| Percentile | Latency, ms |\n| --- | --- |\n")
+		for _, currentPercentile := range currentDevice.LatencyPercentiles {
+			fmt.Fprintf(&builder, "| p%g | %.3f |\n", currentPercentile.Percentile, currentPercentile.Milliseconds)
+		}
+		builder.WriteString("\n| Latency, ms | Count |\n| --- | --- |\n")
+		for _, currentBucket := range currentDevice.LatencyHistogram {
+			fmt.Fprintf(&builder, "| %s | %d |\n", formatHistogramBound(currentBucket.UpperMilliseconds), currentBucket.Count)
+		}
+		fmt.Fprintf(&builder, "\n### Idle gaps\n\n")
+		if len(currentDevice.IdleGaps) == 0 {
+			builder.WriteString("No idle gaps\n")
+			continue
+		}
+		builder.WriteString("| Start | End | Duration, s |\n| --- | --- | --- |\n")
+		for _, currentGap := range currentDevice.IdleGaps {
+			fmt.Fprintf(&builder, "| %s | %s | %.3f |\n", formatReportTime(currentGap.Start), formatReportTime(currentGap.End), currentGap.Seconds)
+		}
+	}
+	_, err = io.WriteString(writer, builder.String())
+	return
+}
+
+func (r *AnalysisReport) WriteHTML(writer io.Writer) error {
+	return analysisHTMLTemplate.Execute(writer, r)
+}
+
+func formatReportTime(timepoint time.Time) string {
+	if conf.DumpTimeLocation != nil {
+		timepoint = timepoint.In(conf.DumpTimeLocation)
+	}
+	return timepoint.Format("2006-01-02 15:04:05.000")
+}
+
+func formatAddressRanges(ranges []AddressRange) string {
+	var rangesText []string
+	for _, currentRange := range ranges {
+		rangesText = append(rangesText, fmt.Sprintf("%s[%d:%d]", currentRange.ObjectType, currentRange.Start, currentRange.End))
+	}
+	return strings.Join(rangesText, ", ")
+}
+
+func formatExceptionCounts(exceptions []ExceptionCount) string {
+	var exceptionsText []string
+	for _, currentException := range exceptions {
+		exceptionsText = append(exceptionsText, fmt.Sprintf("code %d × %d", currentException.Code, currentException.Count))
+	}
+	return strings.Join(exceptionsText, ", ")
+}
+
+func formatHistogramBound(bound float64) string {
+	if math.IsInf(bound, 1) {
+		return "> 1000"
+	}
+	return fmt.Sprintf("≤ %g", bound)
+}
+
+func chartBars(labels []string, counts []int) (bars []chartBar) {
+	maxCount := 1
+	for _, currentCount := range counts {
+		maxCount = max(maxCount, currentCount)
+	}
+	for currentIndex, currentLabel := range labels {
+		bars = append(bars, chartBar{
+			Label: currentLabel,
+			Count: counts[currentIndex],
+			Width: float64(counts[currentIndex]) / float64(maxCount) * (chartWidth - chartLabelsWidth - chartCountsMargin),
+			Y:     currentIndex * chartBarHeight,
+		})
+	}
+	return
+}
+
+var analysisHTMLTemplate = template.Must(template.New("analysis").Funcs(template.FuncMap{
+	"time":        formatReportTime,
+	"ranges":      formatAddressRanges,
+	"exceptions":  formatExceptionCounts,
+	"chartWidth":  func() int { return chartWidth },
+	"labelsWidth": func() int { return chartLabelsWidth },
+	"add":         func(first, second int) int { return first + second },
+	"functionBars": func(functions []FunctionCount) []chartBar {
+		var labels []string
+		var counts []int
+		for _, currentFunction := range functions {
+			labels = append(labels, fmt.Sprintf("function %d", currentFunction.Function))
+			counts = append(counts, currentFunction.Count)
+		}
+		return chartBars(labels, counts)
+	},
+	"latencyBars": func(histogram []HistogramBucket) []chartBar {
+		var labels []string
+		var counts []int
+		for _, currentBucket := range histogram {
+			labels = append(labels, fmt.Sprintf("%s ms", formatHistogramBound(currentBucket.UpperMilliseconds)))
+			counts = append(counts, currentBucket.Count)
+		}
+		return chartBars(labels, counts)
+	},
+	"chartHeight": func(bars []chartBar) int { return len(bars) * chartBarHeight },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Dump analysis: {{.Dump}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+svg text { font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>Dump analysis: {{.Dump}}</h1>
+{{range .Devices}}
+<h2>{{.Socket}} (dump socket {{.DumpSocket}}, {{.Protocol}})</h2>
+<p>Transactions: {{.Transactions}}; time range: {{time .StartTime}} — {{time .EndTime}}; exceptions: {{.Exceptions}}</p>
+<h3>Function codes</h3>
+{{template "chart" functionBars .Functions}}
+<h3>Slaves</h3>
+<table>
+<tr><th>Slave</th><th>Transactions</th><th>Poll cycle, s</th><th>Address ranges</th><th>Exceptions</th></tr>
+{{range .Slaves}}<tr><td>{{.Slave}}</td><td>{{.Transactions}}</td><td>{{printf "%.3f" .PollCyclePeriodSeconds}}</td><td>{{ranges .AddressRanges}}</td><td>{{exceptions .Exceptions}}</td></tr>
+{{end}}</table>
+<h3>Latency</h3>
+<table>
+<tr>{{range .LatencyPercentiles}}<th>p{{.Percentile}}</th>{{end}}</tr>
+<tr>{{range .LatencyPercentiles}}<td>{{printf "%.3f" .Milliseconds}} ms</td>{{end}}</tr>
+</table>
+{{template "chart" latencyBars .LatencyHistogram}}
+<h3>Idle gaps</h3>
+{{if .IdleGaps}}<table>
+<tr><th>Start</th><th>End</th><th>Duration, s</th></tr>
+{{range .IdleGaps}}<tr><td>{{time .Start}}</td><td>{{time .End}}</td><td>{{printf "%.3f" .Seconds}}</td></tr>
+{{end}}</table>{{else}}<p>No idle gaps</p>{{end}}
+{{end}}
+</body>
+</html>
+{{define "chart"}}<svg width="{{chartWidth}}" height="{{chartHeight .}}" xmlns="http://www.w3.org/2000/svg">
+{{range .}}<text x="0" y="{{add .Y 15}}">{{.Label}}</text><rect x="{{labelsWidth}}" y="{{add .Y 3}}" width="{{printf "%.1f" .Width}}" height="16" fill="#4a7fb5"></rect><text x="{{add labelsWidth 4}}" y="{{add .Y 15}}" dx="{{printf "%.1f" .Width}}">{{.Count}}</text>
+{{end}}</svg>{{end}}
+`))
@@ -0,0 +1,135 @@
+package trafficanalysis
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+	"os"
+	"sort"
+
+	"golang.org/x/exp/maps"
+)
+
+const parsedHistoryCacheVersion = "2"
+
+func ParseDumpWithCache() (history map[string]structs.ServerHistory, err error) {
+	if conf.ParsedHistoryCachePath == "" {
+		return ParseDump()
+	}
+	if history, err = LoadParsedHistoryCache(); err == nil {
+		log.Printf("Using parsed history cache \"%s\"", conf.ParsedHistoryCachePath)
+		return
+	}
+	log.Printf("Parsed history cache isn't used: %s", err)
+	if history, err = ParseDump(); err != nil {
+		return
+	}
+	if err = SaveParsedHistoryCache(history); err != nil {
+		log.Printf("Error on saving parsed history cache: %s", err)
+		err = nil
+	}
+	return
+}
+
+func LoadParsedHistoryCache() (history map[string]structs.ServerHistory, err error) {
+	var cacheKey string
+	if cacheKey, err = parsedHistoryCacheKey(); err != nil {
+		return
+	}
+	var cacheFile *os.File
+	if cacheFile, err = os.Open(conf.ParsedHistoryCachePath); err != nil {
+		err = fmt.Errorf("error on opening cache file: %s", err)
+		return
+	}
+	defer cacheFile.Close()
+	decoder := gob.NewDecoder(cacheFile)
+	var currentCacheKey string
+	if err = decoder.Decode(&currentCacheKey); err != nil {
+		err = fmt.Errorf("error on decoding cache key: %s", err)
+		return
+	}
+	if currentCacheKey != cacheKey {
+		err = fmt.Errorf("cache is outdated (dump file or sockets configuration has been changed)")
+		return
+	}
+	if err = decoder.Decode(&history); err != nil {
+		err = fmt.Errorf("error on decoding cached history: %s", err)
+		history = nil
+	}
+	return
+}
+
+func SaveParsedHistoryCache(history map[string]structs.ServerHistory) (err error) {
+	var cacheKey string
+	if cacheKey, err = parsedHistoryCacheKey(); err != nil {
+		return
+	}
+	temporaryPath := fmt.Sprintf("%s.tmp", conf.ParsedHistoryCachePath)
+	var cacheFile *os.File
+	if cacheFile, err = os.OpenFile(temporaryPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666); err != nil {
+		err = fmt.Errorf("error on creating cache file: %s", err)
+		return
+	}
+	encoder := gob.NewEncoder(cacheFile)
+	if err = encoder.Encode(cacheKey); err == nil {
+		err = encoder.Encode(history)
+	}
+	if closeErr := cacheFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temporaryPath)
+		err = fmt.Errorf("error on writing cache file: %s", err)
+		return
+	}
+	if err = os.Rename(temporaryPath, conf.ParsedHistoryCachePath); err != nil {
+		err = fmt.Errorf("error on replacing cache file: %s", err)
+		return
+	}
+	log.Printf("Parsed history cache \"%s\" successfully written", conf.ParsedHistoryCachePath)
+	return
+}
+
+func DumpFileName() (fileName string, err error) {
+	for _, currentExtension := range []string{"pcapng", "pcap"} {
+		fileName = fmt.Sprintf("%s.%s", conf.DumpFilePath, currentExtension)
+		if _, err = os.Stat(fileName); err == nil {
+			return
+		}
+	}
+	err = fmt.Errorf("error on opening file: %s", err)
+	return
+}
+
+func parsedHistoryCacheKey() (key string, err error) {
+	var fileName string
+	if fileName, err = DumpFileName(); err != nil {
+		return
+	}
+	var dumpFile *os.File
+	if dumpFile, err = os.Open(fileName); err != nil {
+		err = fmt.Errorf("error on opening file: %s", err)
+		return
+	}
+	defer dumpFile.Close()
+	hash := sha256.New()
+	if _, err = io.Copy(hash, dumpFile); err != nil {
+		err = fmt.Errorf("error on hashing dump file: %s", err)
+		return
+	}
+	fmt.Fprintf(hash, "\nversion=%s", parsedHistoryCacheVersion)
+	servePaths := maps.Keys(conf.Sockets)
+	sort.Strings(servePaths)
+	for _, currentServePath := range servePaths {
+		currentSocketData := conf.Sockets[currentServePath]
+		fmt.Fprintf(hash, "\n%s=%s:%s/%s",
+			currentServePath, currentSocketData.HostAddress, currentSocketData.PortAddress, currentSocketData.Protocol)
+	}
+	key = hex.EncodeToString(hash.Sum(nil))
+	return
+}
@@ -0,0 +1,353 @@
+package trafficanalysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+	"os"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+const (
+	distributionDifferenceThreshold = 0.25
+	pollRateDifferenceThreshold     = 0.2
+)
+
+type (
+	AddressRange struct {
+		Socket     string `json:"socket"`
+		Slave      uint8  `json:"slave"`
+		ObjectType string `json:"object_type"`
+		Start      uint16 `json:"start"`
+		End        uint16 `json:"end"`
+	}
+	ValueStatistics struct {
+		Samples  int     `json:"samples"`
+		Distinct int     `json:"distinct"`
+		Min      uint16  `json:"min"`
+		Max      uint16  `json:"max"`
+		Mean     float64 `json:"mean"`
+	}
+	DistributionChange struct {
+		Socket     string          `json:"socket"`
+		Slave      uint8           `json:"slave"`
+		ObjectType string          `json:"object_type"`
+		Address    uint16          `json:"address"`
+		Old        ValueStatistics `json:"old"`
+		New        ValueStatistics `json:"new"`
+		Distance   float64         `json:"distance"`
+	}
+	PollRateChange struct {
+		Socket           string  `json:"socket"`
+		Slave            uint8   `json:"slave"`
+		Function         uint16  `json:"function"`
+		Address          uint16  `json:"address"`
+		Quantity         uint16  `json:"quantity"`
+		OldCount         int     `json:"old_count"`
+		NewCount         int     `json:"new_count"`
+		OldPeriodSeconds float64 `json:"old_period_seconds"`
+		NewPeriodSeconds float64 `json:"new_period_seconds"`
+	}
+	ComparisonReport struct {
+		OldDump             string               `json:"old_dump"`
+		NewDump             string               `json:"new_dump"`
+		OnlyInOld           []AddressRange       `json:"only_in_old"`
+		OnlyInNew           []AddressRange       `json:"only_in_new"`
+		DistributionChanges []DistributionChange `json:"distribution_changes"`
+		PollRateChanges     []PollRateChange     `json:"poll_rate_changes"`
+	}
+	registerKey struct {
+		socket     string
+		slave      uint8
+		objectType string
+		address    uint16
+	}
+	pollKey struct {
+		socket   string
+		slave    uint8
+		function uint16
+		address  uint16
+		quantity uint16
+	}
+	historyProfile struct {
+		values map[registerKey]map[uint16]int
+		polls  map[pollKey][]time.Time
+	}
+)
+
+func CompareDumps() (err error) {
+	oldDumpFilePath := conf.DumpFilePath
+	defer func() { conf.DumpFilePath = oldDumpFilePath }()
+	var oldHistory, newHistory map[string]structs.ServerHistory
+	if oldHistory, err = ParseDump(); err != nil {
+		err = fmt.Errorf("error on parsing dump %s: %s", oldDumpFilePath, err)
+		return
+	}
+	conf.DumpFilePath = conf.CompareDumpFilePath
+	if newHistory, err = ParseDump(); err != nil {
+		err = fmt.Errorf("error on parsing dump %s: %s", conf.CompareDumpFilePath, err)
+		return
+	}
+	var report ComparisonReport
+	if report, err = CompareHistories(oldHistory, newHistory); err != nil {
+		return
+	}
+	report.OldDump, report.NewDump = oldDumpFilePath, conf.CompareDumpFilePath
+	return writeReport(report, map[string]func(io.Writer) error{conf.ReportFormats.Text: report.WriteText})
+}
+
+func CompareHistories(oldHistory, newHistory map[string]structs.ServerHistory) (report ComparisonReport, err error) {
+	var oldProfile, newProfile historyProfile
+	if oldProfile, err = newHistoryProfile(oldHistory); err != nil {
+		return
+	}
+	if newProfile, err = newHistoryProfile(newHistory); err != nil {
+		return
+	}
+	report.OnlyInOld = missingAddressRanges(oldProfile, newProfile)
+	report.OnlyInNew = missingAddressRanges(newProfile, oldProfile)
+	for currentKey, currentOldValues := range oldProfile.values {
+		currentNewValues, ok := newProfile.values[currentKey]
+		if !ok {
+			continue
+		}
+		if currentDistance := distributionDistance(currentOldValues, currentNewValues); currentDistance >= distributionDifferenceThreshold {
+			report.DistributionChanges = append(report.DistributionChanges, DistributionChange{
+				Socket:     currentKey.socket,
+				Slave:      currentKey.slave,
+				ObjectType: currentKey.objectType,
+				Address:    currentKey.address,
+				Old:        newValueStatistics(currentOldValues),
+				New:        newValueStatistics(currentNewValues),
+				Distance:   currentDistance,
+			})
+		}
+	}
+	sort.Slice(report.DistributionChanges, func(i, j int) bool {
+		if report.DistributionChanges[i].Distance != report.DistributionChanges[j].Distance {
+			return report.DistributionChanges[i].Distance > report.DistributionChanges[j].Distance
+		}
+		return lessRegisterKey(distributionChangeKey(report.DistributionChanges[i]), distributionChangeKey(report.DistributionChanges[j]))
+	})
+	for currentKey, currentOldTimes := range oldProfile.polls {
+		currentNewTimes, ok := newProfile.polls[currentKey]
+		if !ok || len(currentOldTimes) < 2 || len(currentNewTimes) < 2 {
+			continue
+		}
+		currentOldPeriod, currentNewPeriod := meanPeriod(currentOldTimes), meanPeriod(currentNewTimes)
+		if math.Abs(currentNewPeriod-currentOldPeriod) > pollRateDifferenceThreshold*currentOldPeriod {
+			report.PollRateChanges = append(report.PollRateChanges, PollRateChange{
+				Socket:           currentKey.socket,
+				Slave:            currentKey.slave,
+				Function:         currentKey.function,
+				Address:          currentKey.address,
+				Quantity:         currentKey.quantity,
+				OldCount:         len(currentOldTimes),
+				NewCount:         len(currentNewTimes),
+				OldPeriodSeconds: currentOldPeriod,
+				NewPeriodSeconds: currentNewPeriod,
+			})
+		}
+	}
+	sort.Slice(report.PollRateChanges, func(i, j int) bool {
+		first, second := report.PollRateChanges[i], report.PollRateChanges[j]
+		if first.Socket != second.Socket {
+			return first.Socket < second.Socket
+		}
+		if first.Slave != second.Slave {
+			return first.Slave < second.Slave
+		}
+		if first.Function != second.Function {
+			return first.Function < second.Function
+		}
+		return first.Address < second.Address
+	})
+	return
+}
+
+func (r *ComparisonReport) WriteText(writer io.Writer) (err error) {
+	lines := []string{fmt.Sprintf("Comparison of \"%s\" (old) and \"%s\" (new)", r.OldDump, r.NewDump)}
+	for _, currentRanges := range []struct {
+		title  string
+		ranges []AddressRange
+	}{{"old", r.OnlyInOld}, {"new", r.OnlyInNew}} {
+		lines = append(lines, "", fmt.Sprintf("Address ranges only in %s capture: %d", currentRanges.title, len(currentRanges.ranges)))
+		for _, currentRange := range currentRanges.ranges {
+			lines = append(lines, fmt.Sprintf("  %s slave %d %s[%d:%d]", currentRange.Socket, currentRange.Slave,
+				currentRange.ObjectType, currentRange.Start, currentRange.End))
+		}
+	}
+	lines = append(lines, "", fmt.Sprintf("Value distribution differences: %d", len(r.DistributionChanges)))
+	for _, currentChange := range r.DistributionChanges {
+		lines = append(lines, fmt.Sprintf("  %s slave %d %s[%d]: distance %.2f; old: %s; new: %s", currentChange.Socket, currentChange.Slave,
+			currentChange.ObjectType, currentChange.Address, currentChange.Distance, currentChange.Old, currentChange.New))
+	}
+	lines = append(lines, "", fmt.Sprintf("Poll rate changes: %d", len(r.PollRateChanges)))
+	for _, currentChange := range r.PollRateChanges {
+		lines = append(lines, fmt.Sprintf("  %s slave %d function %d [%d:%d]: period %.3fs -> %.3fs (%d -> %d requests)", currentChange.Socket,
+			currentChange.Slave, currentChange.Function, currentChange.Address, int(currentChange.Address)+int(currentChange.Quantity)-1,
+			currentChange.OldPeriodSeconds, currentChange.NewPeriodSeconds, currentChange.OldCount, currentChange.NewCount))
+	}
+	for _, currentLine := range lines {
+		if _, err = fmt.Fprintln(writer, currentLine); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (vS ValueStatistics) String() string {
+	return fmt.Sprintf("%d samples, %d distinct, min %d, max %d, mean %.2f", vS.Samples, vS.Distinct, vS.Min, vS.Max, vS.Mean)
+}
+
+func writeReport(report any, reportWriters map[string]func(io.Writer) error) (err error) {
+	writer := io.Writer(os.Stdout)
+	if conf.ReportFilePath != "" {
+		var file *os.File
+		if file, err = os.OpenFile(conf.ReportFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666); err != nil {
+			err = fmt.Errorf("error on creating report file: %s", err)
+			return
+		}
+		defer file.Close()
+		writer = file
+	}
+	if conf.ReportFormat == conf.ReportFormats.JSON {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else if reportWriter, ok := reportWriters[conf.ReportFormat]; ok {
+		err = reportWriter(writer)
+	} else {
+		err = fmt.Errorf("invalid report format: %s (must be %s or one of %v)", conf.ReportFormat, conf.ReportFormats.JSON, maps.Keys(reportWriters))
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("error on writing report: %s", err)
+		return
+	}
+	if conf.ReportFilePath != "" {
+		log.Printf("Report successfully written to \"%s\"", conf.ReportFilePath)
+	}
+	return
+}
+
+func newHistoryProfile(history map[string]structs.ServerHistory) (profile historyProfile, err error) {
+	profile = historyProfile{values: make(map[registerKey]map[uint16]int), polls: make(map[pollKey][]time.Time)}
+	for currentServePath, currentHistory := range history {
+		for _, currentEvent := range currentHistory.Transactions {
+			var currentData structs.EmulationData
+			if currentData, err = currentEvent.Handshake.Marshal(); err != nil {
+				err = fmt.Errorf("error on marshaling transaction %s of %s: %s", currentEvent.Header.TransactionID, currentServePath, err)
+				return
+			}
+			currentPollKey := pollKey{currentServePath, currentEvent.Header.SlaveID, currentData.FunctionID &^ 0x80, currentData.Address, currentData.Quantity}
+			profile.polls[currentPollKey] = append(profile.polls[currentPollKey], currentEvent.TransactionTime)
+			if currentEvent.Handshake.TransactionErrorCheck() {
+				continue
+			}
+			objectType := structs.FunctionObjectType(currentData.FunctionID)
+			for currentIndex, currentValue := range currentData.Payload {
+				if currentIndex >= int(currentData.Quantity) || int(currentData.Address)+currentIndex > math.MaxUint16 {
+					break
+				}
+				currentKey := registerKey{currentServePath, currentEvent.Header.SlaveID, objectType, currentData.Address + uint16(currentIndex)}
+				if _, ok := profile.values[currentKey]; !ok {
+					profile.values[currentKey] = make(map[uint16]int)
+				}
+				profile.values[currentKey][currentValue]++
+			}
+		}
+	}
+	return
+}
+
+func missingAddressRanges(profile, anotherProfile historyProfile) []AddressRange {
+	var missingKeys []registerKey
+	for currentKey := range profile.values {
+		if _, ok := anotherProfile.values[currentKey]; !ok {
+			missingKeys = append(missingKeys, currentKey)
+		}
+	}
+	return mergeAddressRanges(missingKeys)
+}
+
+func mergeAddressRanges(keys []registerKey) (ranges []AddressRange) {
+	sort.Slice(keys, func(i, j int) bool { return lessRegisterKey(keys[i], keys[j]) })
+	for _, currentKey := range keys {
+		if len(ranges) != 0 {
+			lastRange := &ranges[len(ranges)-1]
+			if lastRange.Socket == currentKey.socket && lastRange.Slave == currentKey.slave &&
+				lastRange.ObjectType == currentKey.objectType && int(lastRange.End)+1 == int(currentKey.address) {
+				lastRange.End = currentKey.address
+				continue
+			}
+		}
+		ranges = append(ranges, AddressRange{currentKey.socket, currentKey.slave, currentKey.objectType, currentKey.address, currentKey.address})
+	}
+	return
+}
+
+func distributionDistance(firstValues, secondValues map[uint16]int) (distance float64) {
+	var firstSamples, secondSamples int
+	for _, currentCount := range firstValues {
+		firstSamples += currentCount
+	}
+	for _, currentCount := range secondValues {
+		secondSamples += currentCount
+	}
+	for currentValue, currentCount := range firstValues {
+		distance += math.Abs(float64(currentCount)/float64(firstSamples) - float64(secondValues[currentValue])/float64(secondSamples))
+	}
+	for currentValue, currentCount := range secondValues {
+		if _, ok := firstValues[currentValue]; !ok {
+			distance += float64(currentCount) / float64(secondSamples)
+		}
+	}
+	return distance / 2
+}
+
+func newValueStatistics(values map[uint16]int) (statistics ValueStatistics) {
+	statistics.Distinct = len(values)
+	statistics.Min = math.MaxUint16
+	var sum float64
+	for currentValue, currentCount := range values {
+		statistics.Samples += currentCount
+		statistics.Min = min(statistics.Min, currentValue)
+		statistics.Max = max(statistics.Max, currentValue)
+		sum += float64(currentValue) * float64(currentCount)
+	}
+	if statistics.Samples != 0 {
+		statistics.Mean = sum / float64(statistics.Samples)
+	}
+	return
+}
+
+func meanPeriod(times []time.Time) float64 {
+	sortedTimes := append([]time.Time(nil), times...)
+	sort.Slice(sortedTimes, func(i, j int) bool { return sortedTimes[i].Before(sortedTimes[j]) })
+	return sortedTimes[len(sortedTimes)-1].Sub(sortedTimes[0]).Seconds() / float64(len(sortedTimes)-1)
+}
+
+func lessRegisterKey(first, second registerKey) bool {
+	if first.socket != second.socket {
+		return first.socket < second.socket
+	}
+	if first.slave != second.slave {
+		return first.slave < second.slave
+	}
+	if first.objectType != second.objectType {
+		return first.objectType < second.objectType
+	}
+	return first.address < second.address
+}
+
+func distributionChangeKey(change DistributionChange) registerKey {
+	return registerKey{change.Socket, change.Slave, change.ObjectType, change.Address}
+}
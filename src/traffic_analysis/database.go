@@ -0,0 +1,308 @@
+package trafficanalysis
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/maps"
+	_ "modernc.org/sqlite"
+)
+
+const databaseSchemaVersion = "2"
+
+const databaseSchema = `
+CREATE TABLE IF NOT EXISTS metadata (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sockets (
+	id          INTEGER PRIMARY KEY,
+	real_socket TEXT NOT NULL UNIQUE,
+	dump_host   TEXT NOT NULL,
+	dump_port   TEXT NOT NULL,
+	protocol    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS slaves (
+	socket_id INTEGER NOT NULL REFERENCES sockets(id),
+	slave_id  INTEGER NOT NULL,
+	PRIMARY KEY (socket_id, slave_id)
+);
+CREATE TABLE IF NOT EXISTS transactions (
+	id               INTEGER PRIMARY KEY,
+	socket_id        INTEGER NOT NULL REFERENCES sockets(id),
+	position         INTEGER NOT NULL,
+	slave_id         INTEGER NOT NULL,
+	transaction_id   TEXT NOT NULL,
+	transaction_time INTEGER NOT NULL,
+	request_time     INTEGER,
+	time_text        TEXT NOT NULL,
+	function_id      INTEGER NOT NULL,
+	object_type      TEXT NOT NULL,
+	is_read          INTEGER NOT NULL,
+	address          INTEGER NOT NULL,
+	quantity         INTEGER NOT NULL,
+	exception        INTEGER NOT NULL,
+	handshake        BLOB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS register_values (
+	transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+	object_type    TEXT NOT NULL,
+	address        INTEGER NOT NULL,
+	value          INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transactions_socket_position ON transactions (socket_id, position);
+CREATE INDEX IF NOT EXISTS transactions_slave_function ON transactions (slave_id, function_id, address);
+CREATE INDEX IF NOT EXISTS register_values_address ON register_values (object_type, address);
+`
+
+func SaveHistoryToDatabase(history map[string]structs.HistorySource) (err error) {
+	var database *sql.DB
+	if database, err = openDatabase(); err != nil {
+		return
+	}
+	defer database.Close()
+	var historyKey, savedHistoryKey string
+	if historyKey, err = parsedHistoryCacheKey(); err != nil {
+		return
+	}
+	if err = database.QueryRow(`SELECT value FROM metadata WHERE key = 'history_key'`).Scan(&savedHistoryKey); err == nil && savedHistoryKey == historyKey {
+		log.Printf("Database \"%s\" already contains current dump history", conf.DatabasePath)
+		return
+	}
+	var transaction *sql.Tx
+	if transaction, err = database.Begin(); err != nil {
+		err = fmt.Errorf("error on starting database transaction: %s", err)
+		return
+	}
+	defer transaction.Rollback()
+	for _, currentTable := range []string{"register_values", "transactions", "slaves", "sockets", "metadata"} {
+		if _, err = transaction.Exec(fmt.Sprintf("DELETE FROM %s", currentTable)); err != nil {
+			err = fmt.Errorf("error on clearing database table \"%s\": %s", currentTable, err)
+			return
+		}
+	}
+	var transactionStatement, valueStatement *sql.Stmt
+	if transactionStatement, err = transaction.Prepare(`INSERT INTO transactions
+		(socket_id, position, slave_id, transaction_id, transaction_time, request_time, time_text, function_id, object_type, is_read, address, quantity, exception, handshake)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`); err != nil {
+		err = fmt.Errorf("error on preparing database statement: %s", err)
+		return
+	}
+	defer transactionStatement.Close()
+	if valueStatement, err = transaction.Prepare(`INSERT INTO register_values (transaction_id, object_type, address, value) VALUES (?, ?, ?, ?)`); err != nil {
+		err = fmt.Errorf("error on preparing database statement: %s", err)
+		return
+	}
+	defer valueStatement.Close()
+	servePaths := maps.Keys(history)
+	sort.Strings(servePaths)
+	for _, currentServePath := range servePaths {
+		currentSocketData := conf.Sockets[currentServePath]
+		var currentResult sql.Result
+		if currentResult, err = transaction.Exec(`INSERT INTO sockets (real_socket, dump_host, dump_port, protocol) VALUES (?, ?, ?, ?)`,
+			currentServePath, currentSocketData.HostAddress, currentSocketData.PortAddress, currentSocketData.Protocol); err != nil {
+			err = fmt.Errorf("error on saving socket %s: %s", currentServePath, err)
+			return
+		}
+		var currentSocketID int64
+		if currentSocketID, err = currentResult.LastInsertId(); err != nil {
+			err = fmt.Errorf("error on saving socket %s: %s", currentServePath, err)
+			return
+		}
+		currentHistory := history[currentServePath]
+		for _, currentSlaveID := range currentHistory.GetSlaves() {
+			if _, err = transaction.Exec(`INSERT INTO slaves (socket_id, slave_id) VALUES (?, ?)`, currentSocketID, currentSlaveID); err != nil {
+				err = fmt.Errorf("error on saving slave %d: %s", currentSlaveID, err)
+				return
+			}
+		}
+		for currentPosition := 0; currentPosition < currentHistory.Len(); currentPosition++ {
+			var currentEvent structs.HistoryEvent
+			if currentEvent, err = currentHistory.GetEvent(currentPosition); err != nil {
+				return
+			}
+			if err = saveDatabaseTransaction(transactionStatement, valueStatement, currentSocketID, currentPosition, currentEvent); err != nil {
+				err = fmt.Errorf("error on saving transaction %d of %s: %s", currentPosition, currentServePath, err)
+				return
+			}
+		}
+	}
+	if _, err = transaction.Exec(`INSERT INTO metadata (key, value) VALUES ('history_key', ?), ('schema_version', ?)`, historyKey, databaseSchemaVersion); err != nil {
+		err = fmt.Errorf("error on saving database metadata: %s", err)
+		return
+	}
+	if err = transaction.Commit(); err != nil {
+		err = fmt.Errorf("error on commiting database transaction: %s", err)
+		return
+	}
+	log.Printf("Dump history successfully saved to database \"%s\"", conf.DatabasePath)
+	return
+}
+
+func LoadHistoryFromDatabase() (history map[string]structs.ServerHistory, err error) {
+	var database *sql.DB
+	if database, err = openDatabase(); err != nil {
+		return
+	}
+	defer database.Close()
+	var socketRows *sql.Rows
+	if socketRows, err = database.Query(`SELECT id, real_socket, dump_host, dump_port, protocol FROM sockets ORDER BY id`); err != nil {
+		err = fmt.Errorf("error on reading sockets from database: %s", err)
+		return
+	}
+	socketsID := make(map[string]int64)
+	databaseSockets := make(map[string]conf.DumpSocketData)
+	for socketRows.Next() {
+		var currentSocketID int64
+		var currentServePath string
+		var currentSocketData conf.DumpSocketData
+		if err = socketRows.Scan(&currentSocketID, &currentServePath, &currentSocketData.HostAddress, &currentSocketData.PortAddress, &currentSocketData.Protocol); err != nil {
+			socketRows.Close()
+			err = fmt.Errorf("error on reading sockets from database: %s", err)
+			return
+		}
+		socketsID[currentServePath] = currentSocketID
+		databaseSockets[currentServePath] = currentSocketData
+	}
+	socketRows.Close()
+	if len(conf.Sockets) == 0 {
+		conf.Sockets = databaseSockets
+	}
+	history = make(map[string]structs.ServerHistory)
+	for currentServePath := range conf.Sockets {
+		currentSocketID, ok := socketsID[currentServePath]
+		if !ok {
+			err = fmt.Errorf("database doesn't contain history for %s (must be in %v)", currentServePath, maps.Keys(socketsID))
+			return
+		}
+		var currentHistory structs.ServerHistory
+		if currentHistory, err = loadDatabaseSocketHistory(database, currentSocketID); err != nil {
+			err = fmt.Errorf("error on reading history of %s from database: %s", currentServePath, err)
+			return
+		}
+		history[currentServePath] = currentHistory
+	}
+	return
+}
+
+func openDatabase() (database *sql.DB, err error) {
+	if conf.DatabasePath == "" {
+		err = fmt.Errorf("database path isn't set")
+		return
+	}
+	if database, err = sql.Open("sqlite", conf.DatabasePath); err != nil {
+		err = fmt.Errorf("error on opening database: %s", err)
+		return
+	}
+	var schemaVersion string
+	if err = database.QueryRow(`SELECT value FROM metadata WHERE key = 'schema_version'`).Scan(&schemaVersion); err != nil || schemaVersion != databaseSchemaVersion {
+		log.Printf("Database \"%s\" schema is outdated, recreating it", conf.DatabasePath)
+		if _, err = database.Exec(`DROP TABLE IF EXISTS register_values; DROP TABLE IF EXISTS transactions;
+			DROP TABLE IF EXISTS slaves; DROP TABLE IF EXISTS sockets; DROP TABLE IF EXISTS metadata;`); err != nil {
+			database.Close()
+			err = fmt.Errorf("error on dropping outdated database schema: %s", err)
+			return
+		}
+	}
+	if _, err = database.Exec(databaseSchema); err != nil {
+		database.Close()
+		err = fmt.Errorf("error on creating database schema: %s", err)
+		return
+	}
+	if _, err = database.Exec(`INSERT OR IGNORE INTO metadata (key, value) VALUES ('schema_version', ?)`, databaseSchemaVersion); err != nil {
+		database.Close()
+		err = fmt.Errorf("error on saving database schema version: %s", err)
+	}
+	return
+}
+
+func saveDatabaseTransaction(transactionStatement, valueStatement *sql.Stmt, socketID int64, position int, event structs.HistoryEvent) (err error) {
+	var handshakeBuffer bytes.Buffer
+	if err = gob.NewEncoder(&handshakeBuffer).Encode(event.Handshake); err != nil {
+		err = fmt.Errorf("error on encoding handshake: %s", err)
+		return
+	}
+	var emulationData structs.EmulationData
+	if emulationData, err = event.Handshake.Marshal(); err != nil {
+		return
+	}
+	isException := event.Handshake.TransactionErrorCheck()
+	functionID := emulationData.FunctionID &^ 0x80
+	objectType := structs.FunctionObjectType(functionID)
+	var requestTime sql.NullInt64
+	if !event.RequestTime.IsZero() {
+		requestTime = sql.NullInt64{Int64: event.RequestTime.UnixNano(), Valid: true}
+	}
+	var result sql.Result
+	if result, err = transactionStatement.Exec(socketID, position, event.Header.SlaveID, event.Header.TransactionID,
+		event.TransactionTime.UnixNano(), requestTime, event.TransactionTime.Format(time.RFC3339Nano),
+		functionID, objectType, emulationData.IsReadOperation, emulationData.Address, emulationData.Quantity,
+		event.Handshake.GetExceptionCode(), handshakeBuffer.Bytes()); err != nil {
+		return
+	}
+	if isException {
+		return
+	}
+	var transactionID int64
+	if transactionID, err = result.LastInsertId(); err != nil {
+		return
+	}
+	for currentIndex, currentValue := range emulationData.Payload {
+		if currentIndex >= int(emulationData.Quantity) {
+			break
+		}
+		if _, err = valueStatement.Exec(transactionID, objectType, int(emulationData.Address)+currentIndex, currentValue); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func loadDatabaseSocketHistory(database *sql.DB, socketID int64) (history structs.ServerHistory, err error) {
+	var slaveRows *sql.Rows
+	if slaveRows, err = database.Query(`SELECT slave_id FROM slaves WHERE socket_id = ? ORDER BY rowid`, socketID); err != nil {
+		return
+	}
+	for slaveRows.Next() {
+		var currentSlaveID uint8
+		if err = slaveRows.Scan(&currentSlaveID); err != nil {
+			slaveRows.Close()
+			return
+		}
+		history.Slaves = append(history.Slaves, currentSlaveID)
+	}
+	slaveRows.Close()
+	var transactionRows *sql.Rows
+	if transactionRows, err = database.Query(`SELECT slave_id, transaction_id, transaction_time, request_time, handshake
+		FROM transactions WHERE socket_id = ? ORDER BY position`, socketID); err != nil {
+		return
+	}
+	defer transactionRows.Close()
+	for transactionRows.Next() {
+		var currentEvent structs.HistoryEvent
+		var currentTransactionTime int64
+		var currentRequestTime sql.NullInt64
+		var currentHandshake []byte
+		if err = transactionRows.Scan(&currentEvent.Header.SlaveID, &currentEvent.Header.TransactionID, &currentTransactionTime, &currentRequestTime, &currentHandshake); err != nil {
+			return
+		}
+		if err = gob.NewDecoder(bytes.NewReader(currentHandshake)).Decode(&currentEvent.Handshake); err != nil {
+			err = fmt.Errorf("error on decoding handshake: %s", err)
+			return
+		}
+		currentEvent.TransactionTime = time.Unix(0, currentTransactionTime)
+		if currentRequestTime.Valid {
+			currentEvent.RequestTime = time.Unix(0, currentRequestTime.Int64)
+		}
+		history.Transactions = append(history.Transactions, currentEvent)
+	}
+	err = transactionRows.Err()
+	return
+}
@@ -0,0 +1,211 @@
+package trafficanalysis
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+type (
+	ExportRecord struct {
+		Timestamp  time.Time `json:"timestamp"`
+		Socket     string    `json:"socket"`
+		DumpSocket string    `json:"dump_socket"`
+		Slave      uint8     `json:"slave"`
+		Function   uint16    `json:"function"`
+		ObjectType string    `json:"object_type"`
+		Address    uint16    `json:"address"`
+		Quantity   uint16    `json:"quantity"`
+		Values     []uint16  `json:"values"`
+		Exception  uint16    `json:"exception"`
+	}
+	exportWriter interface {
+		WriteRecord(ExportRecord) error
+		Flush() error
+	}
+	csvExportWriter struct {
+		writer *csv.Writer
+	}
+	jsonlExportWriter struct {
+		writer  *bufio.Writer
+		encoder *json.Encoder
+	}
+	influxExportWriter struct {
+		writer *bufio.Writer
+	}
+)
+
+var (
+	exportCSVHeader     = []string{"timestamp", "socket", "dump_socket", "slave", "function", "object_type", "address", "quantity", "values", "exception"}
+	influxTagsEscaper   = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	influxStringEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+func ExportHistory(history map[string]structs.HistorySource) (err error) {
+	fileName := fmt.Sprintf("%s.%s", conf.ExportFilePath, exportFileExtension())
+	var file *os.File
+	if file, err = os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666); err != nil {
+		err = fmt.Errorf("error on creating export file: %s", err)
+		return
+	}
+	defer file.Close()
+	var writer exportWriter
+	if writer, err = newExportWriter(file); err != nil {
+		return
+	}
+	servePaths := maps.Keys(history)
+	sort.Strings(servePaths)
+	var recordsCount int
+	for _, currentServePath := range servePaths {
+		currentHistory := history[currentServePath]
+		for currentIndex := 0; currentIndex < currentHistory.Len(); currentIndex++ {
+			var currentEvent structs.HistoryEvent
+			if currentEvent, err = currentHistory.GetEvent(currentIndex); err != nil {
+				return
+			}
+			var currentRecord ExportRecord
+			if currentRecord, err = NewExportRecord(currentServePath, currentEvent); err != nil {
+				err = fmt.Errorf("error on exporting transaction %d of %s: %s", currentIndex, currentServePath, err)
+				return
+			}
+			if err = writer.WriteRecord(currentRecord); err != nil {
+				err = fmt.Errorf("error on writing export record: %s", err)
+				return
+			}
+			recordsCount++
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		err = fmt.Errorf("error on writing export file: %s", err)
+		return
+	}
+	log.Printf("%d transactions successfully exported to \"%s\"", recordsCount, fileName)
+	return
+}
+
+func NewExportRecord(servePath string, event structs.HistoryEvent) (record ExportRecord, err error) {
+	var emulationData structs.EmulationData
+	if emulationData, err = event.Handshake.Marshal(); err != nil {
+		return
+	}
+	record = ExportRecord{
+		Timestamp:  event.TransactionTime,
+		Socket:     servePath,
+		Slave:      event.Header.SlaveID,
+		Function:   emulationData.FunctionID &^ 0x80,
+		ObjectType: structs.FunctionObjectType(emulationData.FunctionID),
+		Address:    emulationData.Address,
+		Quantity:   emulationData.Quantity,
+		Values:     []uint16{},
+		Exception:  event.Handshake.GetExceptionCode(),
+	}
+	if conf.DumpTimeLocation != nil {
+		record.Timestamp = record.Timestamp.In(conf.DumpTimeLocation)
+	}
+	if currentSocketData, ok := conf.Sockets[servePath]; ok {
+		record.DumpSocket = fmt.Sprintf("%s:%s", currentSocketData.HostAddress, currentSocketData.PortAddress)
+	}
+	if !event.Handshake.TransactionErrorCheck() {
+		record.Values = emulationData.Payload[:min(len(emulationData.Payload), int(emulationData.Quantity))]
+	}
+	return
+}
+
+func exportFileExtension() string {
+	if conf.ExportFormat == conf.ExportFormats.Influx {
+		return "lp"
+	}
+	return conf.ExportFormat
+}
+
+func newExportWriter(file io.Writer) (writer exportWriter, err error) {
+	switch conf.ExportFormat {
+	case conf.ExportFormats.CSV:
+		currentWriter := &csvExportWriter{writer: csv.NewWriter(file)}
+		if err = currentWriter.writer.Write(exportCSVHeader); err != nil {
+			err = fmt.Errorf("error on writing export header: %s", err)
+			return
+		}
+		writer = currentWriter
+	case conf.ExportFormats.JSONL:
+		currentWriter := &jsonlExportWriter{writer: bufio.NewWriter(file)}
+		currentWriter.encoder = json.NewEncoder(currentWriter.writer)
+		writer = currentWriter
+	case conf.ExportFormats.Influx:
+		writer = &influxExportWriter{writer: bufio.NewWriter(file)}
+	default:
+		err = fmt.Errorf("invalid export format: %s (must be %s, %s or %s)", conf.ExportFormat,
+			conf.ExportFormats.CSV, conf.ExportFormats.JSONL, conf.ExportFormats.Influx)
+	}
+	return
+}
+
+func formatExportValues(values []uint16) string {
+	valuesText := make([]string, len(values))
+	for currentIndex, currentValue := range values {
+		valuesText[currentIndex] = strconv.FormatUint(uint64(currentValue), 10)
+	}
+	return strings.Join(valuesText, ";")
+}
+
+func (w *csvExportWriter) WriteRecord(record ExportRecord) error {
+	return w.writer.Write([]string{
+		record.Timestamp.Format(time.RFC3339Nano),
+		record.Socket,
+		record.DumpSocket,
+		strconv.Itoa(int(record.Slave)),
+		strconv.Itoa(int(record.Function)),
+		record.ObjectType,
+		strconv.Itoa(int(record.Address)),
+		strconv.Itoa(int(record.Quantity)),
+		formatExportValues(record.Values),
+		strconv.Itoa(int(record.Exception)),
+	})
+}
+
+func (w *csvExportWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *jsonlExportWriter) WriteRecord(record ExportRecord) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonlExportWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+func (w *influxExportWriter) WriteRecord(record ExportRecord) (err error) {
+	tags := fmt.Sprintf("socket=%s,slave=%d,function=%d", influxTagsEscaper.Replace(record.Socket), record.Slave, record.Function)
+	if record.ObjectType != "" {
+		tags = fmt.Sprintf("%s,object_type=%s", tags, influxTagsEscaper.Replace(record.ObjectType))
+	}
+	if _, err = fmt.Fprintf(w.writer, "modbus_transaction,%s address=%di,quantity=%di,values=\"%s\",exception=%di %d\n", tags,
+		record.Address, record.Quantity, influxStringEscaper.Replace(formatExportValues(record.Values)), record.Exception, record.Timestamp.UnixNano()); err != nil {
+		return
+	}
+	for currentIndex, currentValue := range record.Values {
+		if _, err = fmt.Fprintf(w.writer, "modbus_value,%s,address=%d value=%di %d\n", tags,
+			int(record.Address)+currentIndex, currentValue, record.Timestamp.UnixNano()); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (w *influxExportWriter) Flush() error {
+	return w.writer.Flush()
+}
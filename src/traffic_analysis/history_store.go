@@ -0,0 +1,67 @@
+package trafficanalysis
+
+import (
+	"fmt"
+	"log"
+	"modbus-emulator/conf"
+	historystore "modbus-emulator/src/traffic_analysis/history_store"
+	"modbus-emulator/src/traffic_analysis/structs"
+	"os"
+)
+
+func ParseDumpToStore() (history map[string]structs.HistorySource, err error) {
+	if err = os.MkdirAll(conf.HistoryStorePath, 0777); err != nil {
+		err = fmt.Errorf("error on creating history store directory: %s", err)
+		return
+	}
+	var storeKey string
+	if storeKey, err = parsedHistoryCacheKey(); err != nil {
+		return
+	}
+	history = make(map[string]structs.HistorySource)
+	var openedStores []*historystore.Store
+	var createdStorePaths []string
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, currentStore := range openedStores {
+			currentStore.Close()
+		}
+		for _, currentStorePath := range createdStorePaths {
+			os.Remove(currentStorePath)
+		}
+		history = nil
+	}()
+	for currentPhysicalSocket, currentServerSocketData := range conf.Sockets {
+		currentStorePath := historystore.FilePath(conf.HistoryStorePath, currentPhysicalSocket)
+		var currentStore *historystore.Store
+		if currentStore, err = historystore.Open(currentStorePath, storeKey); err == nil {
+			log.Printf("Using history store \"%s\"", currentStorePath)
+			history[currentPhysicalSocket] = currentStore
+			openedStores = append(openedStores, currentStore)
+			continue
+		}
+		log.Printf("History store \"%s\" isn't used: %s", currentStorePath, err)
+		var currentWriter *historystore.Writer
+		if currentWriter, err = historystore.Create(currentStorePath, storeKey); err != nil {
+			return
+		}
+		createdStorePaths = append(createdStorePaths, currentStorePath)
+		var currentSlavesId []uint8
+		if currentSlavesId, err = parseSocketDump(currentServerSocketData, currentWriter); err != nil {
+			currentWriter.Finish(currentSlavesId)
+			return
+		}
+		if err = currentWriter.Finish(currentSlavesId); err != nil {
+			return
+		}
+		if currentStore, err = historystore.Open(currentStorePath, storeKey); err != nil {
+			return
+		}
+		log.Printf("History store \"%s\" successfully written", currentStorePath)
+		history[currentPhysicalSocket] = currentStore
+		openedStores = append(openedStores, currentStore)
+	}
+	return
+}
@@ -0,0 +1,346 @@
+package historystore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"modbus-emulator/src/traffic_analysis/structs"
+)
+
+const (
+	storeFormat      = "modbus-emulator history store v1"
+	storeExtension   = "history"
+	chunkSize        = 4096
+	cachedChunkLimit = 2
+	footerLength     = 8
+)
+
+type (
+	chunkIndex struct {
+		Offset     int64
+		Length     int64
+		FirstIndex int
+		Count      int
+		FirstTime  time.Time
+		LastTime   time.Time
+	}
+	storeIndex struct {
+		Format string
+		Key    string
+		Slaves []uint8
+		Length int
+		Chunks []chunkIndex
+	}
+	cachedChunk struct {
+		chunkNumber  int
+		transactions []structs.HistoryEvent
+	}
+	Store struct {
+		mutex  sync.Mutex
+		file   *os.File
+		index  storeIndex
+		chunks []cachedChunk
+	}
+	Writer struct {
+		file   *os.File
+		key    string
+		chunks []chunkIndex
+		length int
+		buffer []structs.HistoryEvent
+	}
+)
+
+func FilePath(directory, servePath string) string {
+	return fmt.Sprintf("%s/%s.%s", directory, strings.NewReplacer(":", "_", "/", "_").Replace(servePath), storeExtension)
+}
+
+func Create(path, key string) (writer *Writer, err error) {
+	writer = &Writer{key: key}
+	if writer.file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666); err != nil {
+		err = fmt.Errorf("error on creating history store: %s", err)
+	}
+	return
+}
+
+func (w *Writer) Len() int {
+	return w.length
+}
+
+func (w *Writer) Last() (event *structs.HistoryEvent, err error) {
+	if len(w.buffer) == 0 {
+		if err = w.reloadLastChunk(); err != nil {
+			return
+		}
+	}
+	return &w.buffer[len(w.buffer)-1], nil
+}
+
+func (w *Writer) Append(event structs.HistoryEvent) (err error) {
+	if len(w.buffer) == chunkSize {
+		if err = w.flushChunk(); err != nil {
+			return
+		}
+	}
+	w.buffer = append(w.buffer, event)
+	w.length++
+	return
+}
+
+func (w *Writer) RemoveLast() (err error) {
+	if w.length == 0 {
+		return
+	}
+	if len(w.buffer) == 0 {
+		if err = w.reloadLastChunk(); err != nil {
+			return
+		}
+	}
+	w.buffer = w.buffer[:len(w.buffer)-1]
+	w.length--
+	return
+}
+
+func (w *Writer) Finish(slaves []uint8) (err error) {
+	defer w.file.Close()
+	if err = w.flushChunk(); err != nil {
+		return
+	}
+	var indexOffset int64
+	if indexOffset, err = w.file.Seek(0, io.SeekEnd); err != nil {
+		err = fmt.Errorf("error on writing history store index: %s", err)
+		return
+	}
+	index := storeIndex{
+		Format: storeFormat,
+		Key:    w.key,
+		Slaves: slaves,
+		Length: w.length,
+		Chunks: w.chunks,
+	}
+	if err = gob.NewEncoder(w.file).Encode(index); err != nil {
+		err = fmt.Errorf("error on writing history store index: %s", err)
+		return
+	}
+	footer := make([]byte, footerLength)
+	binary.BigEndian.PutUint64(footer, uint64(indexOffset))
+	if _, err = w.file.Write(footer); err != nil {
+		err = fmt.Errorf("error on writing history store footer: %s", err)
+	}
+	return
+}
+
+func (w *Writer) flushChunk() (err error) {
+	var transactions []structs.HistoryEvent
+	for _, currentEvent := range w.buffer {
+		if currentEvent.Handshake.Request != nil && currentEvent.Handshake.Response != nil {
+			transactions = append(transactions, currentEvent)
+		}
+	}
+	w.length -= len(w.buffer) - len(transactions)
+	w.buffer = nil
+	if len(transactions) == 0 {
+		return
+	}
+	var offset int64
+	if offset, err = w.file.Seek(0, io.SeekEnd); err != nil {
+		err = fmt.Errorf("error on writing history store chunk: %s", err)
+		return
+	}
+	var data []byte
+	if data, err = encodeChunk(transactions); err != nil {
+		return
+	}
+	if _, err = w.file.Write(data); err != nil {
+		err = fmt.Errorf("error on writing history store chunk: %s", err)
+		return
+	}
+	firstIndex := 0
+	if len(w.chunks) != 0 {
+		lastChunk := w.chunks[len(w.chunks)-1]
+		firstIndex = lastChunk.FirstIndex + lastChunk.Count
+	}
+	w.chunks = append(w.chunks, chunkIndex{
+		Offset:     offset,
+		Length:     int64(len(data)),
+		FirstIndex: firstIndex,
+		Count:      len(transactions),
+		FirstTime:  transactions[0].TransactionTime,
+		LastTime:   transactions[len(transactions)-1].TransactionTime,
+	})
+	return
+}
+
+func (w *Writer) reloadLastChunk() (err error) {
+	if len(w.chunks) == 0 {
+		err = fmt.Errorf("error on reloading history store chunk: store is empty")
+		return
+	}
+	lastChunk := w.chunks[len(w.chunks)-1]
+	if w.buffer, err = readChunk(w.file, lastChunk); err != nil {
+		return
+	}
+	if err = w.file.Truncate(lastChunk.Offset); err != nil {
+		err = fmt.Errorf("error on reloading history store chunk: %s", err)
+		return
+	}
+	w.chunks = w.chunks[:len(w.chunks)-1]
+	return
+}
+
+func Open(path, key string) (store *Store, err error) {
+	store = new(Store)
+	if store.file, err = os.Open(path); err != nil {
+		err = fmt.Errorf("error on opening history store: %s", err)
+		return
+	}
+	if err = store.readIndex(); err != nil {
+		store.file.Close()
+		return
+	}
+	if store.index.Format != storeFormat {
+		store.file.Close()
+		err = fmt.Errorf("history store has unknown format \"%s\"", store.index.Format)
+		return
+	}
+	if store.index.Key != key {
+		store.file.Close()
+		err = fmt.Errorf("history store is outdated (dump file or sockets configuration has been changed)")
+	}
+	return
+}
+
+func (s *Store) Len() int {
+	return s.index.Length
+}
+
+func (s *Store) GetEvent(index int) (event structs.HistoryEvent, err error) {
+	if index < 0 || index >= s.index.Length {
+		err = fmt.Errorf("transaction index %d is out of range [0:%d]", index, s.index.Length)
+		return
+	}
+	chunkNumber := sort.Search(len(s.index.Chunks), func(currentChunk int) bool {
+		return s.index.Chunks[currentChunk].FirstIndex+s.index.Chunks[currentChunk].Count > index
+	})
+	var transactions []structs.HistoryEvent
+	if transactions, err = s.loadChunk(chunkNumber); err != nil {
+		return
+	}
+	event = transactions[index-s.index.Chunks[chunkNumber].FirstIndex]
+	return
+}
+
+func (s *Store) GetTransactionTime(index int) (transactionTime time.Time, err error) {
+	var event structs.HistoryEvent
+	if event, err = s.GetEvent(index); err != nil {
+		return
+	}
+	transactionTime = event.TransactionTime
+	return
+}
+
+func (s *Store) SearchTransaction(timepoint time.Time) int {
+	chunkNumber := sort.Search(len(s.index.Chunks), func(currentChunk int) bool {
+		return !timepoint.After(s.index.Chunks[currentChunk].LastTime)
+	})
+	if chunkNumber == len(s.index.Chunks) {
+		return s.index.Length - 1
+	}
+	transactions, err := s.loadChunk(chunkNumber)
+	if err != nil {
+		return -1
+	}
+	return s.index.Chunks[chunkNumber].FirstIndex + sort.Search(len(transactions), func(currentIndex int) bool {
+		return !timepoint.After(transactions[currentIndex].TransactionTime)
+	}) - 1
+}
+
+func (s *Store) GetSlaves() []uint8 {
+	return s.index.Slaves
+}
+
+func (s *Store) Close() error {
+	return s.file.Close()
+}
+
+func (s *Store) readIndex() (err error) {
+	var fileInfo os.FileInfo
+	if fileInfo, err = s.file.Stat(); err != nil {
+		err = fmt.Errorf("error on reading history store: %s", err)
+		return
+	}
+	if fileInfo.Size() < footerLength {
+		err = fmt.Errorf("error on reading history store: file is too short")
+		return
+	}
+	footer := make([]byte, footerLength)
+	if _, err = s.file.ReadAt(footer, fileInfo.Size()-footerLength); err != nil {
+		err = fmt.Errorf("error on reading history store footer: %s", err)
+		return
+	}
+	indexOffset := int64(binary.BigEndian.Uint64(footer))
+	if indexOffset < 0 || indexOffset > fileInfo.Size()-footerLength {
+		err = fmt.Errorf("error on reading history store footer: invalid index offset")
+		return
+	}
+	indexReader := io.NewSectionReader(s.file, indexOffset, fileInfo.Size()-footerLength-indexOffset)
+	if err = gob.NewDecoder(indexReader).Decode(&s.index); err != nil {
+		err = fmt.Errorf("error on reading history store index: %s", err)
+	}
+	return
+}
+
+func (s *Store) loadChunk(chunkNumber int) (transactions []structs.HistoryEvent, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, currentChunk := range s.chunks {
+		if currentChunk.chunkNumber == chunkNumber {
+			transactions = currentChunk.transactions
+			return
+		}
+	}
+	if transactions, err = readChunk(s.file, s.index.Chunks[chunkNumber]); err != nil {
+		return
+	}
+	if len(s.chunks) == cachedChunkLimit {
+		s.chunks = s.chunks[1:]
+	}
+	s.chunks = append(s.chunks, cachedChunk{chunkNumber: chunkNumber, transactions: transactions})
+	return
+}
+
+func encodeChunk(transactions []structs.HistoryEvent) (data []byte, err error) {
+	var buffer bytes.Buffer
+	compressor := gzip.NewWriter(&buffer)
+	if err = gob.NewEncoder(compressor).Encode(transactions); err != nil {
+		err = fmt.Errorf("error on encoding history store chunk: %s", err)
+		return
+	}
+	if err = compressor.Close(); err != nil {
+		err = fmt.Errorf("error on compressing history store chunk: %s", err)
+		return
+	}
+	data = buffer.Bytes()
+	return
+}
+
+func readChunk(file *os.File, chunk chunkIndex) (transactions []structs.HistoryEvent, err error) {
+	var decompressor *gzip.Reader
+	if decompressor, err = gzip.NewReader(io.NewSectionReader(file, chunk.Offset, chunk.Length)); err != nil {
+		err = fmt.Errorf("error on decompressing history store chunk: %s", err)
+		return
+	}
+	defer decompressor.Close()
+	if err = gob.NewDecoder(decompressor).Decode(&transactions); err != nil {
+		err = fmt.Errorf("error on decoding history store chunk: %s", err)
+	}
+	return
+}
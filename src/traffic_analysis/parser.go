@@ -13,104 +13,167 @@ import (
 	"github.com/google/gopacket/pcap"
 )
 
-type protocolDistribution struct {
-	RTUOverTCP uint
-	TCP        uint
-}
+type (
+	protocolDistribution struct {
+		RTUOverTCP uint
+		TCP        uint
+	}
+	historyAccumulator interface {
+		Len() int
+		Last() (*structs.HistoryEvent, error)
+		Append(structs.HistoryEvent) error
+		RemoveLast() error
+	}
+	memoryHistoryAccumulator struct {
+		transactions []structs.HistoryEvent
+	}
+)
 
 func ParseDump() (history map[string]structs.ServerHistory, err error) {
-	var currentHandle *pcap.Handle
 	history = make(map[string]structs.ServerHistory)
 	for currentPhysicalSocket, currentServerSocketData := range conf.Sockets {
-		if currentHandle, err = pcap.OpenOffline(fmt.Sprintf(`%s.pcapng`, conf.DumpFilePath)); err != nil {
-			if currentHandle, err = pcap.OpenOffline(fmt.Sprintf(`%s.pcap`, conf.DumpFilePath)); err != nil {
-				err = fmt.Errorf("error on opening file: %s", err)
-				return
-			}
+		currentAccumulator := new(memoryHistoryAccumulator)
+		var currentSlavesId []uint8
+		if currentSlavesId, err = parseSocketDump(currentServerSocketData, currentAccumulator); err != nil {
+			return
+		}
+		currentPortHistory := structs.ServerHistory{
+			Transactions: currentAccumulator.transactions,
+			Slaves:       currentSlavesId,
 		}
-		if err = currentHandle.SetBPFFilter(fmt.Sprintf("host %s and tcp port %s",
-			currentServerSocketData.HostAddress, currentServerSocketData.PortAddress)); err != nil {
-			err = fmt.Errorf("error on setting handle filter: %s", err)
+		currentPortHistory.SelfClean()
+		history[currentPhysicalSocket] = currentPortHistory
+	}
+	return
+}
+
+func parseSocketDump(serverSocketData conf.DumpSocketData, accumulator historyAccumulator) (slavesId []uint8, err error) {
+	var currentHandle *pcap.Handle
+	if currentHandle, err = pcap.OpenOffline(fmt.Sprintf(`%s.pcapng`, conf.DumpFilePath)); err != nil {
+		if currentHandle, err = pcap.OpenOffline(fmt.Sprintf(`%s.pcap`, conf.DumpFilePath)); err != nil {
+			err = fmt.Errorf("error on opening file: %s", err)
 			return
 		}
-		currentPacketsSource := gopacket.NewPacketSource(currentHandle, currentHandle.LinkType())
-		var currentHistory []structs.HistoryEvent
-		var currentSlavesId []uint8
-		var rtuOverTCPTransactionDictionary map[uint8]int
-		if currentServerSocketData.Protocol == conf.Protocols.RTUOverTCP {
-			rtuOverTCPTransactionDictionary = make(map[uint8]int)
+	}
+	defer currentHandle.Close()
+	if err = currentHandle.SetBPFFilter(fmt.Sprintf("host %s and tcp port %s",
+		serverSocketData.HostAddress, serverSocketData.PortAddress)); err != nil {
+		err = fmt.Errorf("error on setting handle filter: %s", err)
+		return
+	}
+	currentPacketsSource := gopacket.NewPacketSource(currentHandle, currentHandle.LinkType())
+	var rtuOverTCPTransactionDictionary map[uint8]int
+	if serverSocketData.Protocol == conf.Protocols.RTUOverTCP {
+		rtuOverTCPTransactionDictionary = make(map[uint8]int)
+	}
+	for currentPacket := range currentPacketsSource.Packets() {
+		currentTCPLayer := currentPacket.Layer(layers.LayerTypeTCP)
+		currentPayload := currentTCPLayer.LayerPayload()
+		if len(currentPayload) == 0 {
+			continue
 		}
-		for currentPacket := range currentPacketsSource.Packets() {
-			currentTCPLayer := currentPacket.Layer(layers.LayerTypeTCP)
-			currentPayload := currentTCPLayer.LayerPayload()
-			if len(currentPayload) == 0 {
+		currentPacketIsRequest := currentPacket.TransportLayer().TransportFlow().Dst().String() == serverSocketData.PortAddress
+		if !currentPacketIsRequest {
+			if accumulator.Len() == 0 {
 				continue
 			}
-			currentPacketIsRequest := currentPacket.TransportLayer().TransportFlow().Dst().String() == currentServerSocketData.PortAddress
-			if !currentPacketIsRequest {
-				if len(currentHistory) == 0 {
-					continue
+			var lastHistoryEvent *structs.HistoryEvent
+			if lastHistoryEvent, err = accumulator.Last(); err != nil {
+				return
+			}
+			if lastHistoryEvent.Handshake.Response != nil {
+				if serverSocketData.Protocol == conf.Protocols.RTUOverTCP {
+					rtuOverTCPTransactionDictionary[lastHistoryEvent.Header.SlaveID] -= 1
 				}
-				if currentHistory[len(currentHistory)-1].Handshake.Response != nil {
-					if currentServerSocketData.Protocol == conf.Protocols.RTUOverTCP {
-						rtuOverTCPTransactionDictionary[currentHistory[len(currentHistory)-1].Header.SlaveID] -= 1
-					}
-					currentHistory = currentHistory[:len(currentHistory)-1]
-					continue
+				if err = accumulator.RemoveLast(); err != nil {
+					return
 				}
-				currentHistory[len(currentHistory)-1].Handshake.ResponseUnmarshal(currentServerSocketData.Protocol, currentPayload)
-				currentHistory[len(currentHistory)-1].TransactionTime = currentPacket.Metadata().Timestamp
-			} else {
-				if len(currentHistory) != 0 && currentHistory[len(currentHistory)-1].Handshake.Response == nil {
-					if currentServerSocketData.Protocol == conf.Protocols.RTUOverTCP {
-						rtuOverTCPTransactionDictionary[currentHistory[len(currentHistory)-1].Header.SlaveID] -= 1
-					}
-					currentHistory = currentHistory[:len(currentHistory)-1]
-					continue
+				continue
+			}
+			lastHistoryEvent.Handshake.ResponseUnmarshal(serverSocketData.Protocol, currentPayload)
+			lastHistoryEvent.TransactionTime = currentPacket.Metadata().Timestamp
+		} else {
+			if accumulator.Len() != 0 {
+				var lastHistoryEvent *structs.HistoryEvent
+				if lastHistoryEvent, err = accumulator.Last(); err != nil {
+					return
 				}
-				currentHistoryEvent := new(structs.HistoryEvent)
-				switch currentServerSocketData.Protocol {
-				case conf.Protocols.RTUOverTCP:
-					currentSlaveId := uint8(currentPayload[0])
-					if _, ok := rtuOverTCPTransactionDictionary[currentSlaveId]; !ok {
-						rtuOverTCPTransactionDictionary[currentSlaveId] = 1
-					} else {
-						rtuOverTCPTransactionDictionary[currentSlaveId] += 1
+				if lastHistoryEvent.Handshake.Response == nil {
+					if serverSocketData.Protocol == conf.Protocols.RTUOverTCP {
+						rtuOverTCPTransactionDictionary[lastHistoryEvent.Header.SlaveID] -= 1
 					}
-					currentHistoryEvent.Header = structs.SlaveTransaction{
-						SlaveID:       currentSlaveId,
-						TransactionID: strconv.Itoa(rtuOverTCPTransactionDictionary[currentSlaveId]),
+					if err = accumulator.RemoveLast(); err != nil {
+						return
 					}
-				case conf.Protocols.TCP:
-					currentHistoryEvent.Header = structs.SlaveTransaction{
-						SlaveID:       uint8(currentPayload[6]),
-						TransactionID: TCPTransactionIDParsing(currentPayload[:2]),
-					}
-				default:
-					log.Fatalf("Error on parsing dump: %+v has invalid protocol", currentServerSocketData)
+					continue
+				}
+			}
+			currentHistoryEvent := new(structs.HistoryEvent)
+			switch serverSocketData.Protocol {
+			case conf.Protocols.RTUOverTCP:
+				currentSlaveId := uint8(currentPayload[0])
+				if _, ok := rtuOverTCPTransactionDictionary[currentSlaveId]; !ok {
+					rtuOverTCPTransactionDictionary[currentSlaveId] = 1
+				} else {
+					rtuOverTCPTransactionDictionary[currentSlaveId] += 1
+				}
+				currentHistoryEvent.Header = structs.SlaveTransaction{
+					SlaveID:       currentSlaveId,
+					TransactionID: strconv.Itoa(rtuOverTCPTransactionDictionary[currentSlaveId]),
 				}
-				if !slices.Contains(currentSlavesId, currentHistoryEvent.Header.SlaveID) {
-					currentSlavesId = append(currentSlavesId, currentHistoryEvent.Header.SlaveID)
+			case conf.Protocols.TCP:
+				currentHistoryEvent.Header = structs.SlaveTransaction{
+					SlaveID:       uint8(currentPayload[6]),
+					TransactionID: TCPTransactionIDParsing(currentPayload[:2]),
 				}
-				currentHistoryEvent.Handshake.RequestUnmarshal(currentServerSocketData.Protocol, currentPayload)
-				currentHistory = append(currentHistory, *currentHistoryEvent)
+			default:
+				log.Fatalf("Error on parsing dump: %+v has invalid protocol", serverSocketData)
+			}
+			if !slices.Contains(slavesId, currentHistoryEvent.Header.SlaveID) {
+				slavesId = append(slavesId, currentHistoryEvent.Header.SlaveID)
+			}
+			currentHistoryEvent.Handshake.RequestUnmarshal(serverSocketData.Protocol, currentPayload)
+			currentHistoryEvent.RequestTime = currentPacket.Metadata().Timestamp
+			if err = accumulator.Append(*currentHistoryEvent); err != nil {
+				return
 			}
 		}
-		currentHandle.Close()
-		currentPortHistory := structs.ServerHistory{
-			Transactions: currentHistory,
-			Slaves:       currentSlavesId,
-		}
-		currentPortHistory.SelfClean()
-		history[currentPhysicalSocket] = currentPortHistory
 	}
 	return
 }
 
 func SocketAutoAccumulation() (err error) {
+	dumpHosts := make(map[string]protocolDistribution)
+	if err = accumulateDumpHosts(conf.DumpFilePath, dumpHosts); err != nil {
+		return
+	}
+	if conf.WorkMode == conf.WorkModes.Diff {
+		if err = accumulateDumpHosts(conf.CompareDumpFilePath, dumpHosts); err != nil {
+			return
+		}
+	}
+	for currentHost, currentProtocolDefinition := range dumpHosts {
+		currentEmulationSocket := fmt.Sprintf("%s:%d", conf.ServerDefaultEmulateHost, conf.EmulationPortAddressStart)
+		conf.EmulationPortAddressStart++
+		var currentResultProtocol string
+		if currentProtocolDefinition.RTUOverTCP > currentProtocolDefinition.TCP {
+			currentResultProtocol = conf.Protocols.RTUOverTCP
+		} else {
+			currentResultProtocol = conf.Protocols.TCP
+		}
+		conf.Sockets[currentEmulationSocket] = conf.DumpSocketData{
+			HostAddress: currentHost,
+			PortAddress: conf.ServerDefaultDumpPort,
+			Protocol:    currentResultProtocol,
+		}
+	}
+	return
+}
+
+func accumulateDumpHosts(dumpFilePath string, dumpHosts map[string]protocolDistribution) (err error) {
 	var currentHandle *pcap.Handle
-	if currentHandle, err = pcap.OpenOffline(fmt.Sprintf(`%s.pcapng`, conf.DumpFilePath)); err != nil {
-		if currentHandle, err = pcap.OpenOffline(fmt.Sprintf(`%s.pcap`, conf.DumpFilePath)); err != nil {
+	if currentHandle, err = pcap.OpenOffline(fmt.Sprintf(`%s.pcapng`, dumpFilePath)); err != nil {
+		if currentHandle, err = pcap.OpenOffline(fmt.Sprintf(`%s.pcap`, dumpFilePath)); err != nil {
 			err = fmt.Errorf("error on opening file: %s", err)
 			return
 		}
@@ -120,7 +183,6 @@ func SocketAutoAccumulation() (err error) {
 		return
 	}
 	packetsSource := gopacket.NewPacketSource(currentHandle, currentHandle.LinkType())
-	dumpHosts := make(map[string]protocolDistribution)
 	for currentPacket := range packetsSource.Packets() {
 		currentPayload := currentPacket.Layer(layers.LayerTypeTCP).LayerPayload()
 		if len(currentPayload) == 0 {
@@ -147,24 +209,27 @@ func SocketAutoAccumulation() (err error) {
 			}
 		}
 	}
-	for currentHost, currentProtocolDefinition := range dumpHosts {
-		currentEmulationSocket := fmt.Sprintf("%s:%d", conf.ServerDefaultEmulateHost, conf.EmulationPortAddressStart)
-		conf.EmulationPortAddressStart++
-		var currentResultProtocol string
-		if currentProtocolDefinition.RTUOverTCP > currentProtocolDefinition.TCP {
-			currentResultProtocol = conf.Protocols.RTUOverTCP
-		} else {
-			currentResultProtocol = conf.Protocols.TCP
-		}
-		conf.Sockets[currentEmulationSocket] = conf.DumpSocketData{
-			HostAddress: currentHost,
-			PortAddress: conf.ServerDefaultDumpPort,
-			Protocol:    currentResultProtocol,
-		}
-	}
 	return
 }
 
+func (mHA *memoryHistoryAccumulator) Len() int {
+	return len(mHA.transactions)
+}
+
+func (mHA *memoryHistoryAccumulator) Last() (*structs.HistoryEvent, error) {
+	return &mHA.transactions[len(mHA.transactions)-1], nil
+}
+
+func (mHA *memoryHistoryAccumulator) Append(event structs.HistoryEvent) error {
+	mHA.transactions = append(mHA.transactions, event)
+	return nil
+}
+
+func (mHA *memoryHistoryAccumulator) RemoveLast() error {
+	mHA.transactions = mHA.transactions[:len(mHA.transactions)-1]
+	return nil
+}
+
 func TCPTransactionIDParsing(transcationID []byte) (key string) {
 	for _, currentByte := range transcationID {
 		key = fmt.Sprintf("%s-%s", key, strconv.Itoa(int(currentByte)))
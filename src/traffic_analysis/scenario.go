@@ -0,0 +1,296 @@
+package trafficanalysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+const (
+	scenarioReadOperation  = "read"
+	scenarioWriteOperation = "write"
+	scenarioBitsLimit      = 2000 // rows with more values are split into several transactions by the limits of Modbus PDU
+	scenarioRegistersLimit = 123
+)
+
+var scenarioTimeFormats = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", time.DateTime}
+
+type ScenarioEntry struct {
+	Timestamp  string   `json:"timestamp"`
+	Socket     string   `json:"socket"`
+	Slave      uint8    `json:"slave"`
+	ObjectType string   `json:"object_type"`
+	Operation  string   `json:"operation"`
+	Address    uint16   `json:"address"`
+	Values     []uint16 `json:"values"`
+}
+
+func ImportScenario() (history map[string]structs.ServerHistory, err error) {
+	fileName := fmt.Sprintf("%s.%s", conf.DumpFilePath, conf.DumpFormat)
+	var file *os.File
+	if file, err = os.Open(fileName); err != nil {
+		err = fmt.Errorf("error on opening scenario file: %s", err)
+		return
+	}
+	defer file.Close()
+	var entries []ScenarioEntry
+	switch conf.DumpFormat {
+	case conf.DumpFormats.CSV:
+		entries, err = ReadCSVScenario(file)
+	case conf.DumpFormats.JSON:
+		entries, err = ReadJSONScenario(file)
+	default:
+		err = fmt.Errorf("invalid scenario format: %s", conf.DumpFormat)
+	}
+	if err != nil {
+		return
+	}
+	return BuildScenarioHistory(entries)
+}
+
+func ReadCSVScenario(reader io.Reader) (entries []ScenarioEntry, err error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.TrimLeadingSpace = true
+	var header []string
+	if header, err = csvReader.Read(); err != nil {
+		err = fmt.Errorf("error on reading scenario header: %s", err)
+		return
+	}
+	columns := make(map[string]int)
+	for currentIndex, currentColumn := range header {
+		columns[strings.ToLower(strings.TrimSpace(currentColumn))] = currentIndex
+	}
+	for _, currentColumn := range []string{"timestamp", "slave", "object_type", "address", "values"} {
+		if _, ok := columns[currentColumn]; !ok {
+			err = fmt.Errorf("scenario header doesn't contain column \"%s\"", currentColumn)
+			return
+		}
+	}
+	getColumn := func(record []string, column string) string {
+		if currentIndex, ok := columns[column]; ok && currentIndex < len(record) {
+			return strings.TrimSpace(record[currentIndex])
+		}
+		return ""
+	}
+	for {
+		var record []string
+		if record, err = csvReader.Read(); err == io.EOF {
+			err = nil
+			return
+		} else if err != nil {
+			err = fmt.Errorf("error on reading scenario: %s", err)
+			return
+		}
+		line, _ := csvReader.FieldPos(0)
+		currentEntry := ScenarioEntry{
+			Timestamp:  getColumn(record, "timestamp"),
+			Socket:     getColumn(record, "socket"),
+			ObjectType: getColumn(record, "object_type"),
+			Operation:  getColumn(record, "operation"),
+		}
+		var currentNumber uint64
+		if currentNumber, err = strconv.ParseUint(getColumn(record, "slave"), 10, 8); err != nil {
+			err = fmt.Errorf("error on parsing slave on line %d: %s", line, err)
+			return
+		}
+		currentEntry.Slave = uint8(currentNumber)
+		if currentNumber, err = strconv.ParseUint(getColumn(record, "address"), 10, 16); err != nil {
+			err = fmt.Errorf("error on parsing address on line %d: %s", line, err)
+			return
+		}
+		currentEntry.Address = uint16(currentNumber)
+		for _, currentValue := range strings.FieldsFunc(getColumn(record, "values"), func(r rune) bool { return r == ';' || r == ' ' }) {
+			if currentNumber, err = strconv.ParseUint(currentValue, 0, 16); err != nil {
+				err = fmt.Errorf("error on parsing values on line %d: %s", line, err)
+				return
+			}
+			currentEntry.Values = append(currentEntry.Values, uint16(currentNumber))
+		}
+		entries = append(entries, currentEntry)
+	}
+}
+
+func ReadJSONScenario(reader io.Reader) (entries []ScenarioEntry, err error) {
+	if err = json.NewDecoder(reader).Decode(&entries); err != nil {
+		err = fmt.Errorf("error on decoding scenario: %s", err)
+	}
+	return
+}
+
+func BuildScenarioHistory(entries []ScenarioEntry) (history map[string]structs.ServerHistory, err error) {
+	type timedEntry struct {
+		ScenarioEntry
+		transactionTime time.Time
+	}
+	socketsEntries := make(map[string][]timedEntry)
+	for currentIndex, currentEntry := range entries {
+		var currentServePath string
+		if currentServePath, err = scenarioServePath(currentEntry.Socket); err != nil {
+			err = fmt.Errorf("error on entry %d: %s", currentIndex+1, err)
+			return
+		}
+		currentTimedEntry := timedEntry{ScenarioEntry: currentEntry}
+		if currentTimedEntry.transactionTime, err = parseScenarioTime(currentEntry.Timestamp); err != nil {
+			err = fmt.Errorf("error on entry %d: %s", currentIndex+1, err)
+			return
+		}
+		socketsEntries[currentServePath] = append(socketsEntries[currentServePath], currentTimedEntry)
+	}
+	history = make(map[string]structs.ServerHistory)
+	for currentServePath := range conf.Sockets {
+		currentEntries := socketsEntries[currentServePath]
+		sort.SliceStable(currentEntries, func(i, j int) bool {
+			return currentEntries[i].transactionTime.Before(currentEntries[j].transactionTime)
+		})
+		var currentHistory structs.ServerHistory
+		var transactionCounter uint16
+		for _, currentEntry := range currentEntries {
+			var currentEvents []structs.HistoryEvent
+			if currentEvents, err = buildScenarioEvents(currentEntry.ScenarioEntry, &transactionCounter); err != nil {
+				err = fmt.Errorf("error on scenario entry of %s at %s: %s", currentServePath, currentEntry.Timestamp, err)
+				return
+			}
+			for currentIndex := range currentEvents {
+				currentEvents[currentIndex].RequestTime = currentEntry.transactionTime
+				currentEvents[currentIndex].TransactionTime = currentEntry.transactionTime
+			}
+			currentHistory.Transactions = append(currentHistory.Transactions, currentEvents...)
+			if !slices.Contains(currentHistory.Slaves, currentEntry.Slave) {
+				currentHistory.Slaves = append(currentHistory.Slaves, currentEntry.Slave)
+			}
+		}
+		history[currentServePath] = currentHistory
+	}
+	return
+}
+
+func buildScenarioEvents(entry ScenarioEntry, transactionCounter *uint16) (events []structs.HistoryEvent, err error) {
+	if len(entry.Values) == 0 {
+		err = fmt.Errorf("empty values")
+		return
+	}
+	var functionID uint16
+	valuesLimit := scenarioRegistersLimit
+	isWrite := false
+	switch strings.ToLower(entry.Operation) {
+	case "", scenarioReadOperation:
+	case scenarioWriteOperation:
+		isWrite = true
+	default:
+		err = fmt.Errorf("invalid operation \"%s\" (must be %s or %s)", entry.Operation, scenarioReadOperation, scenarioWriteOperation)
+		return
+	}
+	switch strings.ToLower(entry.ObjectType) {
+	case strings.ToLower(conf.ObjectTypes.Coils):
+		functionID = conf.Functions.CoilsRead
+		valuesLimit = scenarioBitsLimit
+		if isWrite {
+			functionID = conf.Functions.CoilsSimpleWrite
+			valuesLimit = 1
+		}
+	case strings.ToLower(conf.ObjectTypes.DI):
+		functionID = conf.Functions.DIRead
+		valuesLimit = scenarioBitsLimit
+		if isWrite {
+			err = fmt.Errorf("object type %s is read-only", entry.ObjectType)
+			return
+		}
+	case strings.ToLower(conf.ObjectTypes.HR):
+		functionID = conf.Functions.HRRead
+		if isWrite {
+			functionID = conf.Functions.HRMultipleWrite
+			if len(entry.Values) == 1 {
+				functionID = conf.Functions.HRSimpleWrite
+			}
+		}
+	case strings.ToLower(conf.ObjectTypes.IR):
+		functionID = conf.Functions.IRRead
+		if isWrite {
+			err = fmt.Errorf("object type %s is read-only", entry.ObjectType)
+			return
+		}
+	default:
+		err = fmt.Errorf("invalid object type \"%s\"", entry.ObjectType)
+		return
+	}
+	if int(entry.Address)+len(entry.Values) > 65536 {
+		err = fmt.Errorf("address range %d-%d is out of range", entry.Address, int(entry.Address)+len(entry.Values)-1)
+		return
+	}
+	for currentStart := 0; currentStart < len(entry.Values); currentStart += valuesLimit {
+		currentValues := entry.Values[currentStart:min(currentStart+valuesLimit, len(entry.Values))]
+		currentAddress := entry.Address + uint16(currentStart)
+		currentQuantity := uint16(len(currentValues))
+		var requestPDU, responsePDU []byte
+		if requestPDU, err = structs.BuildRequestPDU(functionID, currentAddress, currentQuantity, currentValues); err != nil {
+			return
+		}
+		if responsePDU, err = structs.BuildResponsePDU(functionID, currentAddress, currentQuantity, currentValues, 0); err != nil {
+			return
+		}
+		*transactionCounter++
+		currentEvent := structs.HistoryEvent{}
+		currentRequest := structs.BuildTCPADU(*transactionCounter, entry.Slave, requestPDU)
+		currentEvent.Header = structs.SlaveTransaction{
+			SlaveID:       entry.Slave,
+			TransactionID: TCPTransactionIDParsing(currentRequest[:2]),
+		}
+		currentEvent.Handshake.RequestUnmarshal(conf.Protocols.TCP, currentRequest)
+		currentEvent.Handshake.ResponseUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(*transactionCounter, entry.Slave, responsePDU))
+		events = append(events, currentEvent)
+	}
+	return
+}
+
+func scenarioServePath(socket string) (servePath string, err error) {
+	if socket == "" {
+		if len(conf.Sockets) != 1 {
+			err = fmt.Errorf("socket isn't set and sockets configuration contains %d sockets", len(conf.Sockets))
+			return
+		}
+		servePath = maps.Keys(conf.Sockets)[0]
+		return
+	}
+	if _, ok := conf.Sockets[socket]; ok {
+		servePath = socket
+		return
+	}
+	hostAddress, portAddress := socket, conf.ServerDefaultDumpPort
+	if currentSepIndex := strings.Index(socket, ":"); currentSepIndex != -1 {
+		hostAddress, portAddress = socket[:currentSepIndex], socket[currentSepIndex+1:]
+	}
+	for currentServePath, currentSocketData := range conf.Sockets {
+		if currentSocketData.HostAddress == hostAddress && currentSocketData.PortAddress == portAddress {
+			servePath = currentServePath
+			return
+		}
+	}
+	err = fmt.Errorf("socket %s isn't found in sockets configuration", socket)
+	return
+}
+
+func parseScenarioTime(timestamp string) (transactionTime time.Time, err error) {
+	location := conf.DumpTimeLocation
+	if location == nil {
+		location = time.UTC
+	}
+	for _, currentFormat := range scenarioTimeFormats {
+		if transactionTime, err = time.ParseInLocation(currentFormat, timestamp, location); err == nil {
+			return
+		}
+	}
+	err = fmt.Errorf("error on parsing timestamp \"%s\": must be in RFC3339 or \"%s\" format", timestamp, time.DateTime)
+	return
+}
@@ -0,0 +1,127 @@
+package structs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"modbus-emulator/conf"
+)
+
+func BuildRequestPDU(functionID, address, quantity uint16, values []uint16) (pdu []byte, err error) {
+	pdu = []byte{byte(functionID)}
+	pdu = binary.BigEndian.AppendUint16(pdu, address)
+	switch functionID {
+	case conf.Functions.CoilsRead, conf.Functions.DIRead, conf.Functions.HRRead, conf.Functions.IRRead:
+		pdu = binary.BigEndian.AppendUint16(pdu, quantity)
+	case conf.Functions.CoilsSimpleWrite:
+		if len(values) == 0 {
+			err = fmt.Errorf("missing value for simple write")
+			return
+		}
+		pdu = binary.BigEndian.AppendUint16(pdu, coilValue(values[0]))
+	case conf.Functions.HRSimpleWrite:
+		if len(values) == 0 {
+			err = fmt.Errorf("missing value for simple write")
+			return
+		}
+		pdu = binary.BigEndian.AppendUint16(pdu, values[0])
+	case conf.Functions.CoilsMultipleWrite:
+		packedValues := PackBits(values)
+		pdu = binary.BigEndian.AppendUint16(pdu, uint16(len(values)))
+		pdu = append(pdu, byte(len(packedValues)))
+		pdu = append(pdu, packedValues...)
+	case conf.Functions.HRMultipleWrite:
+		pdu = binary.BigEndian.AppendUint16(pdu, uint16(len(values)))
+		pdu = append(pdu, byte(len(values)*2))
+		for _, currentValue := range values {
+			pdu = binary.BigEndian.AppendUint16(pdu, currentValue)
+		}
+	default:
+		err = fmt.Errorf("unsupported function ID: %d", functionID)
+	}
+	return
+}
+
+func BuildResponsePDU(functionID, address, quantity uint16, values []uint16, exceptionCode uint16) (pdu []byte, err error) {
+	if exceptionCode != 0 {
+		pdu = []byte{byte(functionID | 0x80), byte(exceptionCode)}
+		return
+	}
+	pdu = []byte{byte(functionID)}
+	switch functionID {
+	case conf.Functions.CoilsRead, conf.Functions.DIRead:
+		packedValues := PackBits(values)
+		pdu = append(pdu, byte(len(packedValues)))
+		pdu = append(pdu, packedValues...)
+	case conf.Functions.HRRead, conf.Functions.IRRead:
+		pdu = append(pdu, byte(len(values)*2))
+		for _, currentValue := range values {
+			pdu = binary.BigEndian.AppendUint16(pdu, currentValue)
+		}
+	case conf.Functions.CoilsSimpleWrite, conf.Functions.HRSimpleWrite:
+		pdu, err = BuildRequestPDU(functionID, address, quantity, values)
+	case conf.Functions.CoilsMultipleWrite, conf.Functions.HRMultipleWrite:
+		pdu = binary.BigEndian.AppendUint16(pdu, address)
+		pdu = binary.BigEndian.AppendUint16(pdu, quantity)
+	default:
+		err = fmt.Errorf("unsupported function ID: %d", functionID)
+	}
+	return
+}
+
+func BuildTCPADU(transactionID uint16, unitID uint8, pdu []byte) (adu []byte) {
+	adu = binary.BigEndian.AppendUint16(adu, transactionID)
+	adu = append(adu, 0, 0)
+	adu = binary.BigEndian.AppendUint16(adu, uint16(len(pdu)+1))
+	adu = append(adu, unitID)
+	adu = append(adu, pdu...)
+	return
+}
+
+func BuildRTUOverTCPADU(slaveID uint8, pdu []byte) (adu []byte) {
+	adu = append([]byte{slaveID}, pdu...)
+	checksum := CRC16(adu)
+	adu = append(adu, byte(checksum), byte(checksum>>8))
+	return
+}
+
+func CRC16(data []byte) uint16 {
+	checksum := uint16(0xFFFF)
+	for _, currentByte := range data {
+		checksum ^= uint16(currentByte)
+		for currentBit := 0; currentBit < 8; currentBit++ {
+			if checksum&1 != 0 {
+				checksum = checksum>>1 ^ 0xA001
+			} else {
+				checksum >>= 1
+			}
+		}
+	}
+	return checksum
+}
+
+func PackBits(values []uint16) (packedValues []byte) {
+	packedValues = make([]byte, (len(values)+7)/8)
+	for currentIndex, currentValue := range values {
+		if currentValue != 0 {
+			packedValues[currentIndex/8] |= 1 << (currentIndex % 8)
+		}
+	}
+	return
+}
+
+func UnpackBits[T uint16 | byte](packedValues []T, quantity int) (values []uint16) {
+	values = make([]uint16, quantity)
+	for currentIndex := range values {
+		if currentIndex/8 < len(packedValues) && packedValues[currentIndex/8]&(1<<(currentIndex%8)) != 0 {
+			values[currentIndex] = 1
+		}
+	}
+	return
+}
+
+func coilValue(value uint16) uint16 {
+	if value != 0 {
+		return 0xFF00
+	}
+	return 0
+}
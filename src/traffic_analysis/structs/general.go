@@ -1,6 +1,7 @@
 package structs
 
 import (
+	"encoding/gob"
 	"fmt"
 	"log"
 	"modbus-emulator/conf"
@@ -26,6 +27,14 @@ type (
 		Packet
 		GetFunctionID() uint16
 	}
+	HistorySource interface {
+		Len() int
+		GetEvent(int) (HistoryEvent, error)
+		GetTransactionTime(int) (time.Time, error)
+		SearchTransaction(time.Time) int
+		GetSlaves() []uint8
+		Close() error
+	}
 
 	SlaveTransaction struct {
 		SlaveID       uint8
@@ -34,6 +43,7 @@ type (
 	HistoryEvent struct {
 		Header          SlaveTransaction
 		Handshake       Handshake
+		RequestTime     time.Time
 		TransactionTime time.Time
 	}
 	ServerHistory struct {
@@ -53,6 +63,24 @@ type (
 	}
 )
 
+func init() {
+	gob.Register(new(TCPRequest))
+	gob.Register(new(TCPResponse))
+	gob.Register(new(TCPReadRequest))
+	gob.Register(new(TCPReadBitResponse))
+	gob.Register(new(TCPReadByteResponse))
+	gob.Register(new(TCPWriteSimpleRequest))
+	gob.Register(new(TCPWriteMultipleRequest))
+	gob.Register(new(TCPWriteSimpleResponse))
+	gob.Register(new(TCPWriteMultipleResponse))
+	gob.Register(new(TCPErrorResponse))
+	gob.Register(new(RTUOverTCPErrorResponse))
+	gob.Register(new(RTUOverTCPRequest123456Response56))
+	gob.Register(new(RTUOverTCPReadResponse))
+	gob.Register(new(RTUOverTCPMultipleWriteRequest))
+	gob.Register(new(RTUOverTCPMultipleWriteResponse))
+}
+
 func (hE *HistoryEvent) LogPrint() {
 	log.Printf("\n\nSlave ID: %d\n", hE.Header.SlaveID)
 	log.Printf("\n Transaction № %s", hE.Header.TransactionID)
@@ -131,6 +159,9 @@ func (hdhk *Handshake) Marshal() (data EmulationData, err error) {
 			err = fmt.Errorf("error marshaling current handshake: %s", err)
 			return
 		}
+		if len(data.Payload) > int(data.Quantity) {
+			data.Payload = data.Payload[:data.Quantity]
+		}
 		if len(data.Payload) != int(data.Quantity) {
 			for {
 				if len(data.Payload) == int(data.Quantity) {
@@ -152,6 +183,71 @@ func (hdhk *Handshake) TransactionErrorCheck() bool {
 	return hdhk.Response.GetFunctionID()>>7 == 0b1
 }
 
+func (hdhk *Handshake) GetExceptionCode() uint16 {
+	if !hdhk.TransactionErrorCheck() {
+		return 0
+	}
+	switch response := hdhk.Response.(type) {
+	case *RTUOverTCPErrorResponse:
+		return response.ErrorCode
+	case *TCPResponse:
+		if errorResponse, ok := response.Data.(*TCPErrorResponse); ok {
+			return uint16(errorResponse.ErrorCode)
+		}
+	}
+	return 0
+}
+
+func FunctionObjectType(functionID uint16) string {
+	switch functionID &^ 0x80 {
+	case conf.Functions.CoilsRead, conf.Functions.CoilsSimpleWrite, conf.Functions.CoilsMultipleWrite:
+		return conf.ObjectTypes.Coils
+	case conf.Functions.DIRead:
+		return conf.ObjectTypes.DI
+	case conf.Functions.HRRead, conf.Functions.HRSimpleWrite, conf.Functions.HRMultipleWrite:
+		return conf.ObjectTypes.HR
+	case conf.Functions.IRRead:
+		return conf.ObjectTypes.IR
+	}
+	return ""
+}
+
+func (sH *ServerHistory) Len() int {
+	return len(sH.Transactions)
+}
+
+func (sH *ServerHistory) GetEvent(index int) (event HistoryEvent, err error) {
+	if index < 0 || index >= len(sH.Transactions) {
+		err = fmt.Errorf("transaction index %d is out of range [0:%d]", index, len(sH.Transactions))
+		return
+	}
+	event = sH.Transactions[index]
+	return
+}
+
+func (sH *ServerHistory) GetTransactionTime(index int) (transactionTime time.Time, err error) {
+	var event HistoryEvent
+	if event, err = sH.GetEvent(index); err != nil {
+		return
+	}
+	transactionTime = event.TransactionTime
+	return
+}
+
+func (sH *ServerHistory) SearchTransaction(timepoint time.Time) int {
+	return sort.Search(len(sH.Transactions), func(index int) bool {
+		return !timepoint.After(sH.Transactions[index].TransactionTime)
+	}) - 1
+}
+
+func (sH *ServerHistory) GetSlaves() []uint8 {
+	return sH.Slaves
+}
+
+func (sH *ServerHistory) Close() error {
+	return nil
+}
+
 func (sH *ServerHistory) SelfClean() {
 	deleteIndices := []int{}
 	for currentIndex, currentHistoryEvent := range sH.Transactions {
@@ -165,9 +261,18 @@ func (sH *ServerHistory) SelfClean() {
 	}
 }
 
+func HistorySourcesFromMemory(history map[string]ServerHistory) (sources map[string]HistorySource) {
+	sources = make(map[string]HistorySource)
+	for currentServePath := range history {
+		currentHistory := history[currentServePath]
+		sources[currentServePath] = &currentHistory
+	}
+	return
+}
+
 func InputsPayloadPreprocessing[T uint16 | byte](data []T) (payload []uint16, err error) {
 	for _, currentByte := range data {
-		currentBinaryByte := strings.Split(strconv.FormatUint(uint64(currentByte), 2), "")
+		currentBinaryByte := strings.Split(fmt.Sprintf("%08b", uint64(currentByte)), "")
 		for currentIndex := len(currentBinaryByte) - 1; currentIndex > -1; currentIndex-- {
 			var currentIntBuffer int
 			if currentIntBuffer, err = strconv.Atoi(currentBinaryByte[currentIndex]); err != nil {
@@ -195,7 +300,7 @@ func RegistersPayloadPreprocessing[T uint16 | byte](data []T) (payload []uint16,
 func BytesToDecimal[T uint16 | byte](bytes []T) (result uint16, err error) {
 	var hexBuffer string
 	for _, curretnByte := range bytes {
-		hexBuffer = fmt.Sprintf("%s%s", hexBuffer, strconv.FormatUint(uint64(curretnByte), 16))
+		hexBuffer = fmt.Sprintf("%s%02x", hexBuffer, uint64(curretnByte))
 	}
 	var resultBuffer uint64
 	if resultBuffer, err = strconv.ParseUint(hexBuffer, 16, 64); err != nil {
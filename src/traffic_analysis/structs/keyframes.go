@@ -0,0 +1,131 @@
+package structs
+
+import (
+	"fmt"
+
+	"modbus-emulator/conf"
+
+	"golang.org/x/exp/maps"
+)
+
+type (
+	RegisterAddress struct {
+		SlaveID    uint8
+		ObjectType string
+		Address    uint16
+	}
+	RegistersImage map[RegisterAddress]uint16
+	Keyframe       struct {
+		TransactionIndex int
+		Registers        RegistersImage
+	}
+	Keyframes struct {
+		Interval  int
+		Frames    []Keyframe
+		Addresses []RegisterAddress
+	}
+)
+
+func BuildKeyframes(history HistorySource, interval int) (keyframes Keyframes, err error) {
+	if interval < 1 {
+		err = fmt.Errorf("invalid keyframe interval: %d", interval)
+		return
+	}
+	keyframes.Interval = interval
+	image := make(RegistersImage)
+	for currentIndex := 0; currentIndex < history.Len(); currentIndex++ {
+		if currentIndex%interval == 0 {
+			keyframes.Frames = append(keyframes.Frames, Keyframe{TransactionIndex: currentIndex, Registers: maps.Clone(image)})
+		}
+		var currentEvent HistoryEvent
+		if currentEvent, err = history.GetEvent(currentIndex); err != nil {
+			err = fmt.Errorf("error on building keyframes: %s", err)
+			return
+		}
+		if err = image.Apply(currentEvent); err != nil {
+			err = fmt.Errorf("error on building keyframes on transaction %d: %s", currentIndex, err)
+			return
+		}
+	}
+	keyframes.Addresses = maps.Keys(image)
+	return
+}
+
+func (k *Keyframes) Image(history HistorySource, transactionIndex int) (image RegistersImage, err error) {
+	if transactionIndex < 0 || transactionIndex > history.Len() {
+		err = fmt.Errorf("transaction index %d is out of range [0:%d]", transactionIndex, history.Len())
+		return
+	}
+	if len(k.Frames) == 0 {
+		return make(RegistersImage), nil
+	}
+	frame := k.Frames[min(transactionIndex/k.Interval, len(k.Frames)-1)]
+	image = maps.Clone(frame.Registers)
+	for currentIndex := frame.TransactionIndex; currentIndex < transactionIndex; currentIndex++ {
+		var currentEvent HistoryEvent
+		if currentEvent, err = history.GetEvent(currentIndex); err != nil {
+			return
+		}
+		if err = image.Apply(currentEvent); err != nil {
+			err = fmt.Errorf("error on applying transaction %d: %s", currentIndex, err)
+			return
+		}
+	}
+	return
+}
+
+func (k *Keyframes) Lookahead(history HistorySource, transactionIndex int, knownImage RegistersImage) (image RegistersImage, err error) {
+	if transactionIndex < 0 || transactionIndex > history.Len() {
+		err = fmt.Errorf("transaction index %d is out of range [0:%d]", transactionIndex, history.Len())
+		return
+	}
+	image = make(RegistersImage)
+	missingCount := 0
+	for _, currentAddress := range k.Addresses {
+		if _, ok := knownImage[currentAddress]; !ok {
+			missingCount++
+		}
+	}
+	for currentIndex := transactionIndex; currentIndex < history.Len() && len(image) < missingCount; currentIndex++ {
+		var currentEvent HistoryEvent
+		if currentEvent, err = history.GetEvent(currentIndex); err != nil {
+			return
+		}
+		currentImage := make(RegistersImage)
+		if err = currentImage.Apply(currentEvent); err != nil {
+			err = fmt.Errorf("error on applying transaction %d: %s", currentIndex, err)
+			return
+		}
+		for currentAddress, currentValue := range currentImage {
+			if _, ok := knownImage[currentAddress]; ok {
+				continue
+			}
+			if _, ok := image[currentAddress]; !ok {
+				image[currentAddress] = currentValue
+			}
+		}
+	}
+	return
+}
+
+func (rI RegistersImage) Apply(event HistoryEvent) (err error) {
+	if event.Handshake.TransactionErrorCheck() {
+		return
+	}
+	var data EmulationData
+	if data, err = event.Handshake.Marshal(); err != nil {
+		return
+	}
+	objectType := FunctionObjectType(data.FunctionID)
+	if objectType == "" {
+		return
+	}
+	quantity := min(len(data.Payload), int(data.Quantity))
+	if data.FunctionID == conf.Functions.CoilsSimpleWrite || data.FunctionID == conf.Functions.HRSimpleWrite {
+		quantity = min(len(data.Payload), 1)
+	}
+	for currentIndex := 0; currentIndex < quantity && int(data.Address)+currentIndex <= 0xffff; currentIndex++ {
+		rI[RegisterAddress{event.Header.SlaveID, objectType, data.Address + uint16(currentIndex)}] = data.Payload[currentIndex]
+	}
+	return
+}
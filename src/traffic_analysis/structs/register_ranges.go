@@ -0,0 +1,93 @@
+package structs
+
+import (
+	"fmt"
+	"slices"
+
+	"modbus-emulator/conf"
+)
+
+type (
+	RegisterRange struct {
+		SlaveID    uint8  `json:"slave_id"`
+		ObjectType string `json:"object_type"`
+		Start      uint16 `json:"start"`
+		End        uint16 `json:"end"`
+	}
+	WritePolicyRange struct {
+		RegisterRange
+		Policy    string `json:"policy"`
+		Exception uint8  `json:"exception,omitempty"`
+	}
+	WritePolicySettings struct {
+		Policy    string             `json:"policy"`
+		Exception uint8              `json:"exception"`
+		Ranges    []WritePolicyRange `json:"ranges"`
+	}
+	ForcedRange struct {
+		RegisterRange
+		Values []uint16 `json:"values"`
+	}
+)
+
+func (wPS *WritePolicySettings) Resolve(writtenRange RegisterRange) (policy string, exception uint8) {
+	for currentIndex := len(wPS.Ranges) - 1; currentIndex >= 0; currentIndex-- {
+		if currentRange := wPS.Ranges[currentIndex]; currentRange.Overlaps(writtenRange) {
+			return currentRange.Policy, currentRange.Exception
+		}
+	}
+	return wPS.Policy, wPS.Exception
+}
+
+func (rR RegisterRange) Overlaps(other RegisterRange) bool {
+	return rR.SlaveID == other.SlaveID && rR.ObjectType == other.ObjectType && rR.Start <= other.End && other.Start <= rR.End
+}
+
+func (fR ForcedRange) Value(address uint16) (value uint16, ok bool) {
+	if address < fR.Start || address > fR.End {
+		return
+	}
+	return fR.Values[address-fR.Start], true
+}
+
+func UnforceRange(forces []ForcedRange, addressRange RegisterRange) (remainingForces []ForcedRange) {
+	for _, currentForce := range forces {
+		if !currentForce.Overlaps(addressRange) {
+			remainingForces = append(remainingForces, currentForce)
+			continue
+		}
+		if currentForce.Start < addressRange.Start {
+			leftForce := currentForce
+			leftForce.End = addressRange.Start - 1
+			leftForce.Values = slices.Clone(currentForce.Values[:addressRange.Start-currentForce.Start])
+			remainingForces = append(remainingForces, leftForce)
+		}
+		if currentForce.End > addressRange.End {
+			rightForce := currentForce
+			rightForce.Start = addressRange.End + 1
+			rightForce.Values = slices.Clone(currentForce.Values[addressRange.End+1-currentForce.Start:])
+			remainingForces = append(remainingForces, rightForce)
+		}
+	}
+	return
+}
+
+func CheckRegistersRange(objectType string, from uint16, count, size int) (err error) {
+	if count < 1 {
+		err = fmt.Errorf("invalid \"count\" parameter (must be positive integer)")
+	} else if int(from)+count > size {
+		err = fmt.Errorf("range [%d:%d] is out of %s bounds [0:%d]", from, int(from)+count, objectType, size)
+	}
+	return
+}
+
+func CheckRegistersValues(objectType string, count int, values []uint16) (err error) {
+	if count != len(values) {
+		return fmt.Errorf("\"count\" parameter (%d) isn't equal to payload values count (%d)", count, len(values))
+	}
+	isBits := objectType == conf.ObjectTypes.Coils || objectType == conf.ObjectTypes.DI
+	if index := slices.IndexFunc(values, func(value uint16) bool { return isBits && value > 1 }); index != -1 {
+		err = fmt.Errorf("invalid payload value %d on position %d (must be 0 or 1 for %s)", values[index], index, objectType)
+	}
+	return
+}
@@ -0,0 +1,100 @@
+package structs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"modbus-emulator/conf"
+)
+
+type RequestKey struct {
+	SlaveID    uint8  `json:"slave_id"`
+	FunctionID uint16 `json:"function_id"`
+	Address    uint16 `json:"address"`
+	Quantity   uint16 `json:"quantity"`
+}
+
+func NewRequestKey(slaveID uint8, functionID uint16, data []byte) (key RequestKey, err error) {
+	if len(data) < 4 {
+		err = fmt.Errorf("request data is too short: %d bytes", len(data))
+		return
+	}
+	return normalizeRequestKey(RequestKey{
+		SlaveID:    slaveID,
+		FunctionID: functionID &^ 0x80,
+		Address:    binary.BigEndian.Uint16(data[0:2]),
+		Quantity:   binary.BigEndian.Uint16(data[2:4]),
+	}), nil
+}
+
+func (hE *HistoryEvent) RequestKey() (key RequestKey, err error) {
+	if hE.Handshake.Request == nil || hE.Handshake.Response == nil {
+		err = fmt.Errorf("transaction isn't complete")
+		return
+	}
+	key.SlaveID, key.FunctionID = hE.Header.SlaveID, hE.Handshake.Response.GetFunctionID()&^0x80
+	if key.Address, err = BytesToDecimal(hE.Handshake.Request.MarshalAddress()); err != nil {
+		err = fmt.Errorf("error on marshaling request key address: %s", err)
+		return
+	}
+	if key.Quantity, err = BytesToDecimal(hE.Handshake.Request.MarshalQuantity()); err != nil {
+		err = fmt.Errorf("error on marshaling request key quantity: %s", err)
+		return
+	}
+	return normalizeRequestKey(key), nil
+}
+
+func NewRequestValues(functionID uint16, data []byte) (values []uint16) {
+	switch functionID &^ 0x80 {
+	case conf.Functions.CoilsSimpleWrite:
+		if len(data) >= 4 {
+			values = []uint16{min(binary.BigEndian.Uint16(data[2:4]), 1)}
+		}
+	case conf.Functions.HRSimpleWrite:
+		if len(data) >= 4 {
+			values = []uint16{binary.BigEndian.Uint16(data[2:4])}
+		}
+	case conf.Functions.CoilsMultipleWrite:
+		if len(data) >= 5 {
+			values = UnpackBits(data[5:], int(binary.BigEndian.Uint16(data[2:4])))
+		}
+	case conf.Functions.HRMultipleWrite:
+		for currentIndex := 5; currentIndex+1 < len(data); currentIndex += 2 {
+			values = append(values, binary.BigEndian.Uint16(data[currentIndex:currentIndex+2]))
+		}
+	}
+	return
+}
+
+func (hE *HistoryEvent) RequestValues() (values []uint16, err error) {
+	var key RequestKey
+	if key, err = hE.RequestKey(); err != nil {
+		return
+	}
+	if !slices.Contains([]uint16{conf.Functions.CoilsSimpleWrite, conf.Functions.HRSimpleWrite, conf.Functions.CoilsMultipleWrite, conf.Functions.HRMultipleWrite}, key.FunctionID) {
+		return
+	}
+	if values, err = hE.Handshake.Request.MarshalPayload(); err != nil {
+		err = fmt.Errorf("error on marshaling request values: %s", err)
+		return
+	}
+	switch key.FunctionID {
+	case conf.Functions.CoilsSimpleWrite:
+		if len(values) > 0 {
+			values = []uint16{min(values[0], 1)}
+		}
+	case conf.Functions.CoilsMultipleWrite:
+		if len(values) != int(key.Quantity) {
+			values = UnpackBits(values, int(key.Quantity))
+		}
+	}
+	return
+}
+
+func normalizeRequestKey(key RequestKey) RequestKey {
+	if key.FunctionID == conf.Functions.CoilsSimpleWrite || key.FunctionID == conf.Functions.HRSimpleWrite {
+		key.Quantity = 1
+	}
+	return key
+}
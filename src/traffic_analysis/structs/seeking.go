@@ -0,0 +1,97 @@
+package structs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"modbus-emulator/conf"
+)
+
+func SeekTransaction(history HistorySource, currentIndex int, mode, value string) (transactionIndex int, err error) {
+	if history.Len() == 0 {
+		err = fmt.Errorf("history is empty")
+		return
+	}
+	lastIndex := history.Len() - 1
+	switch mode {
+	case conf.SeekModes.TransactionIndex:
+		if transactionIndex, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("invalid transaction index %s: %s", value, err)
+			return
+		}
+		if transactionIndex < 0 || transactionIndex > lastIndex {
+			err = fmt.Errorf("transaction index must be in range [0:%d]", lastIndex)
+		}
+	case conf.SeekModes.Offset:
+		var offset time.Duration
+		if offset, err = time.ParseDuration(value); err != nil {
+			err = fmt.Errorf("invalid offset %s: %s", value, err)
+			return
+		}
+		var currentTime time.Time
+		if currentTime, err = history.GetTransactionTime(min(max(currentIndex, 0), lastIndex)); err != nil {
+			return
+		}
+		transactionIndex = searchLastTransaction(history, currentTime.Add(offset))
+	case conf.SeekModes.Percentage:
+		var percentage float64
+		if percentage, err = strconv.ParseFloat(value, 64); err != nil {
+			err = fmt.Errorf("invalid percentage %s: %s", value, err)
+			return
+		}
+		if percentage < 0 || percentage > 100 {
+			err = fmt.Errorf("percentage must be in range [0:100]")
+			return
+		}
+		var startTime, endTime time.Time
+		if startTime, err = history.GetTransactionTime(0); err != nil {
+			return
+		}
+		if endTime, err = history.GetTransactionTime(lastIndex); err != nil {
+			return
+		}
+		transactionIndex = searchLastTransaction(history, startTime.Add(time.Duration(float64(endTime.Sub(startTime))*percentage/100)))
+	case conf.SeekModes.Position:
+		switch value {
+		case conf.SeekPositions.Start:
+			transactionIndex = 0
+		case conf.SeekPositions.End:
+			transactionIndex = lastIndex
+		default:
+			err = fmt.Errorf("position must be %s or %s", conf.SeekPositions.Start, conf.SeekPositions.End)
+		}
+	default:
+		err = fmt.Errorf("invalid seek mode: %s", mode)
+	}
+	return
+}
+
+func searchLastTransaction(history HistorySource, timepoint time.Time) int {
+	return min(max(history.SearchTransaction(timepoint.Add(time.Nanosecond)), 0), history.Len()-1)
+}
+
+func SearchSegment(history HistorySource, inTime, outTime time.Time) (inIndex, outIndex int, err error) {
+	if history.Len() == 0 {
+		err = fmt.Errorf("history is empty")
+		return
+	}
+	inIndex, outIndex = 0, history.Len()-1
+	if !inTime.IsZero() {
+		if inIndex = history.SearchTransaction(inTime) + 1; inIndex > outIndex {
+			err = fmt.Errorf("loop-in time %s is after end of dump", inTime)
+			return
+		}
+		inIndex = max(inIndex, 0)
+	}
+	if !outTime.IsZero() {
+		if outIndex = min(history.SearchTransaction(outTime.Add(time.Nanosecond)), history.Len()-1); outIndex < 0 {
+			err = fmt.Errorf("loop-out time %s is before start of dump", outTime)
+			return
+		}
+	}
+	if inIndex > outIndex {
+		err = fmt.Errorf("loop-in time must be before loop-out time")
+	}
+	return
+}
@@ -37,7 +37,8 @@ type (
 	}
 	TCPReadBitResponse struct { // for coils and DI
 		NumberBits byte
-		Bits       byte // like: [0, 1]
+		Bits       byte   // like: [0, 1]
+		NextBits   []byte // bytes after first one, for more than 8 bits
 	}
 	TCPReadByteResponse struct { // for HR and IR
 		NumberBits byte
@@ -59,6 +60,9 @@ type (
 		AddressStart           []byte
 		NumberWrittenRegisters []byte
 	}
+	TCPErrorResponse struct {
+		ErrorCode byte
+	}
 	TCPMarshaledData struct {
 		AddressStart []byte
 		CheckField   []byte
@@ -198,6 +202,8 @@ func (pRes *TCPResponse) UnmarshalData(payload []byte) {
 		pRes.Data = new(TCPWriteSimpleResponse)
 	} else if slices.Contains([]byte{byte(conf.Functions.CoilsMultipleWrite), byte(conf.Functions.HRMultipleWrite)}, pRes.Header.FunctionType) {
 		pRes.Data = new(TCPWriteMultipleResponse)
+	} else {
+		pRes.Data = new(TCPErrorResponse)
 	}
 	pRes.Data.Unmarshal(payload)
 }
@@ -237,7 +243,7 @@ func (rBiRes *TCPReadBitResponse) GetQuantityRegisters() []uint16 {
 }
 
 func (rBiRes *TCPReadBitResponse) MarshalPayload() (payload []uint16, err error) {
-	if payload, err = InputsPayloadPreprocessing([]byte{rBiRes.Bits}); err != nil {
+	if payload, err = InputsPayloadPreprocessing(append([]byte{rBiRes.Bits}, rBiRes.NextBits...)); err != nil {
 		err = fmt.Errorf("error on marshaling read data: %s", err)
 	}
 	return
@@ -246,11 +252,14 @@ func (rBiRes *TCPReadBitResponse) MarshalPayload() (payload []uint16, err error)
 func (rBiRes *TCPReadBitResponse) Unmarshal(payload []byte) {
 	rBiRes.NumberBits = payload[8]
 	rBiRes.Bits = payload[9]
+	if len(payload) > 10 {
+		rBiRes.NextBits = payload[10:min(len(payload), 9+int(rBiRes.NumberBits))]
+	}
 }
 
 func (rBiRes *TCPReadBitResponse) LogPrint() {
 	log.Printf("   Count response bit: %v\n", rBiRes.NumberBits)
-	log.Printf("   Response bit: %v\n", rBiRes.Bits)
+	log.Printf("   Response bit: %v\n", append([]byte{rBiRes.Bits}, rBiRes.NextBits...))
 }
 
 func (rByRes *TCPReadByteResponse) GetQuantityRegisters() []uint16 {
@@ -378,3 +387,23 @@ func (wMRes *TCPWriteMultipleResponse) LogPrint() {
 	log.Printf("   Address start: %v\n", wMRes.AddressStart)
 	log.Printf("   Number written registers: %v\n", wMRes.NumberWrittenRegisters)
 }
+
+func (eRes *TCPErrorResponse) GetQuantityRegisters() []uint16 {
+	return []uint16{}
+}
+
+func (eRes *TCPErrorResponse) MarshalPayload() ([]uint16, error) {
+	return []uint16{}, nil
+}
+
+func (eRes *TCPErrorResponse) Unmarshal(payload []byte) {
+	if len(payload) < 9 {
+		log.Println("Error: insufficient payload length")
+		return
+	}
+	eRes.ErrorCode = payload[8]
+}
+
+func (eRes *TCPErrorResponse) LogPrint() {
+	log.Printf("   Error code: %v\n", eRes.ErrorCode)
+}
@@ -0,0 +1,218 @@
+package trafficanalysis
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"golang.org/x/exp/maps"
+)
+
+const (
+	verificationLookahead = 100
+	maximalDeviations     = 1000
+)
+
+type (
+	VerifiedRequest struct {
+		structs.RequestKey
+		Values []uint16 `json:"values,omitempty"`
+	}
+	Deviation struct {
+		Position     int              `json:"position"`
+		ReceivedTime string           `json:"received_time"`
+		Kind         string           `json:"kind"`
+		Expected     *VerifiedRequest `json:"expected,omitempty"`
+		Received     VerifiedRequest  `json:"received"`
+		Skipped      int              `json:"skipped,omitempty"`
+	}
+	ServerVerification struct {
+		Socket          string         `json:"socket"`
+		Expected        int            `json:"expected"`
+		Received        int            `json:"received"`
+		Matched         int            `json:"matched"`
+		Missing         int            `json:"missing"`
+		Loops           int            `json:"loops"`
+		DeviationsCount map[string]int `json:"deviations_count"`
+		Deviations      []Deviation    `json:"deviations"`
+		IsFinished      bool           `json:"is_finished"`
+		Passed          bool           `json:"passed"`
+	}
+	VerificationReport struct {
+		Passed     bool                 `json:"passed"`
+		IsFinished bool                 `json:"is_finished"`
+		Servers    []ServerVerification `json:"servers"`
+	}
+	Verifier struct {
+		mutex   sync.Mutex
+		servers map[string]*serverVerifier
+	}
+	serverVerifier struct {
+		expected []VerifiedRequest
+		position int
+		result   ServerVerification
+	}
+)
+
+func NewVerifier(history map[string]structs.HistorySource) (verifier *Verifier, err error) {
+	verifier = &Verifier{servers: make(map[string]*serverVerifier)}
+	for currentServePath, currentHistory := range history {
+		currentVerifier := &serverVerifier{result: ServerVerification{Socket: currentServePath, DeviationsCount: make(map[string]int)}}
+		for currentIndex := 0; currentIndex < currentHistory.Len(); currentIndex++ {
+			var currentEvent structs.HistoryEvent
+			if currentEvent, err = currentHistory.GetEvent(currentIndex); err != nil {
+				err = fmt.Errorf("error on reading history of %s: %s", currentServePath, err)
+				return
+			}
+			var currentRequest VerifiedRequest
+			if currentRequest.RequestKey, err = currentEvent.RequestKey(); err != nil {
+				err = fmt.Errorf("error on reading transaction %d of %s: %s", currentIndex, currentServePath, err)
+				return
+			}
+			if currentRequest.Values, err = currentEvent.RequestValues(); err != nil {
+				err = fmt.Errorf("error on reading transaction %d of %s: %s", currentIndex, currentServePath, err)
+				return
+			}
+			currentVerifier.expected = append(currentVerifier.expected, currentRequest)
+		}
+		currentVerifier.result.Expected = len(currentVerifier.expected)
+		verifier.servers[currentServePath] = currentVerifier
+	}
+	return
+}
+
+func (v *Verifier) Check(servePath string, request VerifiedRequest, receivedTime time.Time) (deviation *Deviation) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	sV, ok := v.servers[servePath]
+	if !ok || len(sV.expected) == 0 {
+		return
+	}
+	sV.result.Received++
+	if sV.position == len(sV.expected) {
+		sV.position = 0
+		sV.result.Loops++
+	}
+	position, expected := sV.position, sV.expected[sV.position]
+	if expected.RequestKey == request.RequestKey {
+		if slices.Equal(expected.Values, request.Values) {
+			sV.result.Matched++
+			sV.position++
+			return
+		}
+		deviation = &Deviation{Kind: conf.DeviationKinds.Values, Expected: &expected}
+		sV.position++
+	} else if index := sV.search(request); index != -1 {
+		deviation = &Deviation{Kind: conf.DeviationKinds.Order, Expected: &expected, Skipped: index - sV.position}
+		if slices.Equal(sV.expected[index].Values, request.Values) {
+			sV.result.Matched++
+		}
+		sV.position = index + 1
+	} else {
+		deviation = &Deviation{Kind: conf.DeviationKinds.Unexpected, Expected: &expected}
+	}
+	deviation.Position, deviation.ReceivedTime, deviation.Received = position, receivedTime.String(), request
+	sV.result.DeviationsCount[deviation.Kind]++
+	if len(sV.result.Deviations) < maximalDeviations {
+		sV.result.Deviations = append(sV.result.Deviations, *deviation)
+	}
+	return
+}
+
+func (v *Verifier) Finish(servePath string) (isAllFinished bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if sV, ok := v.servers[servePath]; ok {
+		sV.result.IsFinished = true
+	}
+	for _, currentVerifier := range v.servers {
+		if !currentVerifier.result.IsFinished {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *Verifier) Report() (report VerificationReport) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	report.Passed, report.IsFinished = true, true
+	servePaths := maps.Keys(v.servers)
+	sort.Strings(servePaths)
+	for _, currentServePath := range servePaths {
+		sV := v.servers[currentServePath]
+		result := sV.result
+		result.DeviationsCount = maps.Clone(sV.result.DeviationsCount)
+		result.Deviations = slices.Clone(sV.result.Deviations)
+		result.Missing = len(sV.expected) - sV.position
+		deviationsCount := 0
+		for _, currentCount := range result.DeviationsCount {
+			deviationsCount += currentCount
+		}
+		result.Passed = deviationsCount == 0 && result.Missing == 0
+		report.Passed = report.Passed && result.Passed
+		report.IsFinished = report.IsFinished && result.IsFinished
+		report.Servers = append(report.Servers, result)
+	}
+	return
+}
+
+func WriteVerificationReport(report VerificationReport) error {
+	return writeReport(report, map[string]func(io.Writer) error{conf.ReportFormats.Text: report.WriteText})
+}
+
+func (r *VerificationReport) WriteText(writer io.Writer) (err error) {
+	status := map[bool]string{true: "PASSED", false: "FAILED"}
+	lines := []string{fmt.Sprintf("Verification: %s", status[r.Passed])}
+	for _, currentServer := range r.Servers {
+		lines = append(lines, "", fmt.Sprintf("Server %s: %s", currentServer.Socket, status[currentServer.Passed]),
+			fmt.Sprintf("  expected: %d, received: %d, matched: %d, missing: %d, loops: %d", currentServer.Expected, currentServer.Received,
+				currentServer.Matched, currentServer.Missing, currentServer.Loops))
+		for _, currentKind := range []string{conf.DeviationKinds.Order, conf.DeviationKinds.Values, conf.DeviationKinds.Unexpected} {
+			lines = append(lines, fmt.Sprintf("  %s deviations: %d", currentKind, currentServer.DeviationsCount[currentKind]))
+		}
+		for _, currentDeviation := range currentServer.Deviations {
+			lines = append(lines, fmt.Sprintf("  %s", currentDeviation.String()))
+		}
+	}
+	for _, currentLine := range lines {
+		if _, err = fmt.Fprintln(writer, currentLine); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (d Deviation) String() string {
+	description := fmt.Sprintf("[%d] %s %s: received %s", d.Position, d.ReceivedTime, d.Kind, d.Received)
+	if d.Expected != nil {
+		description = fmt.Sprintf("%s, expected %s", description, *d.Expected)
+	}
+	if d.Skipped > 0 {
+		description = fmt.Sprintf("%s, skipped %d", description, d.Skipped)
+	}
+	return description
+}
+
+func (vR VerifiedRequest) String() string {
+	description := fmt.Sprintf("slave %d function %d [%d:%d]", vR.SlaveID, vR.FunctionID, vR.Address, int(vR.Address)+int(vR.Quantity)-1)
+	if len(vR.Values) != 0 {
+		description = fmt.Sprintf("%s = %v", description, vR.Values)
+	}
+	return description
+}
+
+func (sV *serverVerifier) search(request VerifiedRequest) int {
+	for currentIndex := sV.position + 1; currentIndex < min(sV.position+verificationLookahead, len(sV.expected)); currentIndex++ {
+		if sV.expected[currentIndex].RequestKey == request.RequestKey {
+			return currentIndex
+		}
+	}
+	return -1
+}
@@ -0,0 +1,201 @@
+package trafficgeneration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+	trafficrecording "modbus-emulator/src/traffic_recording"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+const DefaultClientSocket = "192.0.2.1:49152"
+
+type (
+	Transaction struct {
+		Client          string        `json:"client"`
+		Server          string        `json:"server"`
+		Protocol        string        `json:"protocol"`
+		Timestamp       time.Time     `json:"timestamp"`
+		ResponseDelay   time.Duration `json:"-"`
+		SlaveID         uint8         `json:"slave"`
+		TransactionID   uint16        `json:"transaction_id"`
+		FunctionID      uint16        `json:"function"`
+		Address         uint16        `json:"address"`
+		Quantity        uint16        `json:"quantity"`
+		Values          []uint16      `json:"values"`
+		ExceptionCode   uint16        `json:"exception"`
+		Fragments       int           `json:"fragments"`
+		WithoutResponse bool          `json:"without_response"`
+	}
+	timedPacket struct {
+		timestamp time.Time
+		data      []byte
+	}
+)
+
+func (t *Transaction) UnmarshalJSON(data []byte) (err error) {
+	type transactionAlias Transaction
+	transaction := struct {
+		*transactionAlias
+		ResponseDelay string `json:"response_delay"`
+	}{transactionAlias: (*transactionAlias)(t)}
+	if err = json.Unmarshal(data, &transaction); err != nil {
+		return
+	}
+	if transaction.ResponseDelay != "" {
+		if t.ResponseDelay, err = time.ParseDuration(transaction.ResponseDelay); err != nil {
+			err = fmt.Errorf("error on parsing response delay: %s", err)
+		}
+	}
+	return
+}
+
+func ReadTransactions(reader io.Reader) (transactions []Transaction, err error) {
+	if err = json.NewDecoder(reader).Decode(&transactions); err != nil {
+		err = fmt.Errorf("error on decoding transactions: %s", err)
+	}
+	return
+}
+
+func GenerateFile(path string, transactions []Transaction) (err error) {
+	var file *os.File
+	if file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666); err != nil {
+		err = fmt.Errorf("error on creating fixture file: %s", err)
+		return
+	}
+	defer file.Close()
+	return Generate(file, transactions)
+}
+
+func Generate(writer io.Writer, transactions []Transaction) (err error) {
+	var packets []timedPacket
+	if packets, err = buildPackets(transactions); err != nil {
+		return
+	}
+	var ngWriter *pcapgo.NgWriter
+	if ngWriter, err = pcapgo.NewNgWriter(writer, layers.LinkTypeEthernet); err != nil {
+		err = fmt.Errorf("error on creating pcapng writer: %s", err)
+		return
+	}
+	for _, currentPacket := range packets {
+		captureInfo := gopacket.CaptureInfo{Timestamp: currentPacket.timestamp, CaptureLength: len(currentPacket.data), Length: len(currentPacket.data)}
+		if err = ngWriter.WritePacket(captureInfo, currentPacket.data); err != nil {
+			err = fmt.Errorf("error on writing packet: %s", err)
+			return
+		}
+	}
+	if err = ngWriter.Flush(); err != nil {
+		err = fmt.Errorf("error on writing fixture: %s", err)
+	}
+	return
+}
+
+func BuildADUs(transaction Transaction) (request, response []byte, err error) {
+	var requestPDU, responsePDU []byte
+	if requestPDU, err = structs.BuildRequestPDU(transaction.FunctionID, transaction.Address, transaction.Quantity, transaction.Values); err != nil {
+		return
+	}
+	if responsePDU, err = structs.BuildResponsePDU(transaction.FunctionID, transaction.Address, transaction.Quantity, transaction.Values, transaction.ExceptionCode); err != nil {
+		return
+	}
+	switch transaction.Protocol {
+	case conf.Protocols.TCP:
+		request = structs.BuildTCPADU(transaction.TransactionID, transaction.SlaveID, requestPDU)
+		response = structs.BuildTCPADU(transaction.TransactionID, transaction.SlaveID, responsePDU)
+	case conf.Protocols.RTUOverTCP:
+		request = structs.BuildRTUOverTCPADU(transaction.SlaveID, requestPDU)
+		response = structs.BuildRTUOverTCPADU(transaction.SlaveID, responsePDU)
+	default:
+		err = fmt.Errorf("invalid protocol: %s", transaction.Protocol)
+	}
+	return
+}
+
+func buildPackets(transactions []Transaction) (packets []timedPacket, err error) {
+	type segment struct {
+		conversationKey string
+		timestamp       time.Time
+		fromClient      bool
+		payload         []byte
+	}
+	var segments []segment
+	conversations := make(map[string]*trafficrecording.Conversation)
+	transactionCounters := make(map[string]uint16)
+	for currentIndex, currentTransaction := range transactions {
+		if currentTransaction.Client == "" {
+			currentTransaction.Client = DefaultClientSocket
+		}
+		if currentTransaction.Protocol == "" {
+			currentTransaction.Protocol = conf.Protocols.TCP
+		}
+		if currentTransaction.Quantity == 0 {
+			currentTransaction.Quantity = uint16(len(currentTransaction.Values))
+		}
+		conversationKey := fmt.Sprintf("%s-%s", currentTransaction.Client, currentTransaction.Server)
+		if _, ok := conversations[conversationKey]; !ok {
+			if conversations[conversationKey], err = trafficrecording.NewConversation(currentTransaction.Client, currentTransaction.Server); err != nil {
+				err = fmt.Errorf("error on transaction %d: %s", currentIndex, err)
+				return
+			}
+		}
+		if currentTransaction.TransactionID == 0 {
+			transactionCounters[conversationKey]++
+			currentTransaction.TransactionID = transactionCounters[conversationKey]
+		}
+		var request, response []byte
+		if request, response, err = BuildADUs(currentTransaction); err != nil {
+			err = fmt.Errorf("error on transaction %d: %s", currentIndex, err)
+			return
+		}
+		for _, currentFragment := range splitPayload(request, currentTransaction.Fragments) {
+			segments = append(segments, segment{conversationKey, currentTransaction.Timestamp, true, currentFragment})
+		}
+		if currentTransaction.WithoutResponse {
+			continue
+		}
+		for _, currentFragment := range splitPayload(response, currentTransaction.Fragments) {
+			segments = append(segments, segment{conversationKey, currentTransaction.Timestamp.Add(currentTransaction.ResponseDelay), false, currentFragment})
+		}
+	}
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].timestamp.Before(segments[j].timestamp)
+	})
+	for _, currentSegment := range segments {
+		conversation := conversations[currentSegment.conversationKey]
+		var currentPackets [][]byte
+		if !conversation.IsOpened() {
+			if currentPackets, err = conversation.HandshakePackets(); err != nil {
+				return
+			}
+		}
+		var currentPacket []byte
+		if currentPacket, err = conversation.SegmentPacket(currentSegment.fromClient, currentSegment.payload); err != nil {
+			return
+		}
+		for _, currentData := range append(currentPackets, currentPacket) {
+			packets = append(packets, timedPacket{timestamp: currentSegment.timestamp, data: currentData})
+		}
+	}
+	return
+}
+
+func splitPayload(payload []byte, fragments int) (parts [][]byte) {
+	if fragments < 2 {
+		return [][]byte{payload}
+	}
+	fragments = min(fragments, len(payload))
+	partSize := (len(payload) + fragments - 1) / fragments
+	for currentStart := 0; currentStart < len(payload); currentStart += partSize {
+		parts = append(parts, payload[currentStart:min(currentStart+partSize, len(payload))])
+	}
+	return
+}
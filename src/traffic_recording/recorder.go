@@ -0,0 +1,214 @@
+package trafficrecording
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+const (
+	initialSequence = 1000
+	windowSize      = 65535
+)
+
+var (
+	clientMAC = net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	serverMAC = net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x02}
+)
+
+type (
+	Conversation struct {
+		ClientIP       net.IP
+		ClientPort     uint16
+		ServerIP       net.IP
+		ServerPort     uint16
+		clientSequence uint32
+		serverSequence uint32
+		isOpened       bool
+	}
+	Recorder struct {
+		mutex         sync.Mutex
+		file          *os.File
+		writer        *pcapgo.NgWriter
+		conversations map[string]*Conversation
+	}
+)
+
+func NewConversation(clientSocket, serverSocket string) (conversation *Conversation, err error) {
+	conversation = new(Conversation)
+	if conversation.ClientIP, conversation.ClientPort, err = parseSocket(clientSocket); err != nil {
+		return
+	}
+	conversation.ServerIP, conversation.ServerPort, err = parseSocket(serverSocket)
+	return
+}
+
+func NewRecorder(path string) (recorder *Recorder, err error) {
+	recorder = &Recorder{conversations: make(map[string]*Conversation)}
+	if recorder.file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666); err != nil {
+		err = fmt.Errorf("error on creating record file: %s", err)
+		return
+	}
+	if recorder.writer, err = pcapgo.NewNgWriter(recorder.file, layers.LinkTypeEthernet); err != nil {
+		recorder.file.Close()
+		err = fmt.Errorf("error on creating pcapng writer: %s", err)
+		return
+	}
+	if err = recorder.writer.Flush(); err != nil {
+		recorder.file.Close()
+		err = fmt.Errorf("error on writing record file: %s", err)
+	}
+	return
+}
+
+func (r *Recorder) WriteTransaction(clientSocket, serverSocket string, requestTime, responseTime time.Time, request, response []byte) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	conversationKey := fmt.Sprintf("%s-%s", clientSocket, serverSocket)
+	conversation, ok := r.conversations[conversationKey]
+	if !ok {
+		if conversation, err = NewConversation(clientSocket, serverSocket); err != nil {
+			return
+		}
+		r.conversations[conversationKey] = conversation
+	}
+	var packets [][]byte
+	if packets, err = conversation.TransactionPackets(request, response); err != nil {
+		return
+	}
+	for currentIndex, currentPacket := range packets {
+		timestamp := requestTime
+		if currentIndex == len(packets)-1 {
+			timestamp = responseTime
+		}
+		captureInfo := gopacket.CaptureInfo{Timestamp: timestamp, CaptureLength: len(currentPacket), Length: len(currentPacket)}
+		if err = r.writer.WritePacket(captureInfo, currentPacket); err != nil {
+			err = fmt.Errorf("error on writing packet to record file: %s", err)
+			return
+		}
+	}
+	if err = r.writer.Flush(); err != nil {
+		err = fmt.Errorf("error on writing record file: %s", err)
+	}
+	return
+}
+
+func (r *Recorder) Close() (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err = r.writer.Flush(); err != nil {
+		r.file.Close()
+		return
+	}
+	return r.file.Close()
+}
+
+func (c *Conversation) TransactionPackets(request, response []byte) (packets [][]byte, err error) {
+	if !c.isOpened {
+		if packets, err = c.HandshakePackets(); err != nil {
+			return
+		}
+	}
+	var currentPacket []byte
+	if currentPacket, err = c.SegmentPacket(true, request); err != nil {
+		return
+	}
+	packets = append(packets, currentPacket)
+	if currentPacket, err = c.SegmentPacket(false, response); err != nil {
+		return
+	}
+	packets = append(packets, currentPacket)
+	return
+}
+
+func (c *Conversation) IsOpened() bool {
+	return c.isOpened
+}
+
+func (c *Conversation) HandshakePackets() (packets [][]byte, err error) {
+	c.clientSequence, c.serverSequence = initialSequence, initialSequence
+	for _, currentFlags := range []struct {
+		fromClient bool
+		tcp        layers.TCP
+	}{
+		{true, layers.TCP{SYN: true}},
+		{false, layers.TCP{SYN: true, ACK: true}},
+		{true, layers.TCP{ACK: true}},
+	} {
+		var currentPacket []byte
+		if currentPacket, err = c.BuildPacket(currentFlags.fromClient, currentFlags.tcp, nil); err != nil {
+			return
+		}
+		packets = append(packets, currentPacket)
+		if currentFlags.tcp.SYN {
+			if currentFlags.fromClient {
+				c.clientSequence++
+			} else {
+				c.serverSequence++
+			}
+		}
+	}
+	c.isOpened = true
+	return
+}
+
+func (c *Conversation) SegmentPacket(fromClient bool, payload []byte) (packet []byte, err error) {
+	if packet, err = c.BuildPacket(fromClient, layers.TCP{ACK: true, PSH: true}, payload); err != nil {
+		return
+	}
+	if fromClient {
+		c.clientSequence += uint32(len(payload))
+	} else {
+		c.serverSequence += uint32(len(payload))
+	}
+	return
+}
+
+func (c *Conversation) BuildPacket(fromClient bool, tcp layers.TCP, payload []byte) (packet []byte, err error) {
+	ethernet := layers.Ethernet{SrcMAC: clientMAC, DstMAC: serverMAC, EthernetType: layers.EthernetTypeIPv4}
+	ip := layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP, SrcIP: c.ClientIP, DstIP: c.ServerIP}
+	tcp.SrcPort, tcp.DstPort = layers.TCPPort(c.ClientPort), layers.TCPPort(c.ServerPort)
+	tcp.Seq, tcp.Ack = c.clientSequence, c.serverSequence
+	if !fromClient {
+		ethernet.SrcMAC, ethernet.DstMAC = serverMAC, clientMAC
+		ip.SrcIP, ip.DstIP = c.ServerIP, c.ClientIP
+		tcp.SrcPort, tcp.DstPort = tcp.DstPort, tcp.SrcPort
+		tcp.Seq, tcp.Ack = c.serverSequence, c.clientSequence
+	}
+	if tcp.SYN && !tcp.ACK {
+		tcp.Ack = 0
+	}
+	tcp.Window = windowSize
+	if err = tcp.SetNetworkLayerForChecksum(&ip); err != nil {
+		err = fmt.Errorf("error on building packet: %s", err)
+		return
+	}
+	buffer := gopacket.NewSerializeBuffer()
+	if err = gopacket.SerializeLayers(buffer, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+		&ethernet, &ip, &tcp, gopacket.Payload(payload)); err != nil {
+		err = fmt.Errorf("error on building packet: %s", err)
+		return
+	}
+	packet = buffer.Bytes()
+	return
+}
+
+func parseSocket(socket string) (ip net.IP, port uint16, err error) {
+	var tcpAddress *net.TCPAddr
+	if tcpAddress, err = net.ResolveTCPAddr("tcp", socket); err != nil {
+		err = fmt.Errorf("error on parsing socket %s: %s", socket, err)
+		return
+	}
+	if ip = tcpAddress.IP.To4(); ip == nil {
+		err = fmt.Errorf("error on parsing socket %s: isn't IPv4 socket", socket)
+		return
+	}
+	port = uint16(tcpAddress.Port)
+	return
+}
@@ -0,0 +1,158 @@
+package src
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	mS "github.com/Daniil-Kurganov/modbus-server"
+)
+
+type (
+	writeEvent struct {
+		Time       string   `json:"time"`
+		ServerID   int      `json:"server_id"`
+		SlaveID    uint8    `json:"slave_id"`
+		FunctionID uint16   `json:"function_id"`
+		ObjectType string   `json:"object_type"`
+		Address    uint16   `json:"address"`
+		Values     []uint16 `json:"values"`
+		Policy     string   `json:"policy"`
+		Exception  uint8    `json:"exception,omitempty"`
+	}
+)
+
+const maximalWriteEvents = 1000
+
+var writeFunctions = []uint16{conf.Functions.CoilsSimpleWrite, conf.Functions.HRSimpleWrite, conf.Functions.CoilsMultipleWrite, conf.Functions.HRMultipleWrite}
+
+func writePolicyFunctionHandler(handler functionHandler) functionHandler {
+	return func(server *mS.Server, request mS.Framer) (data []byte, exception *mS.Exception) {
+		key, err := structs.NewRequestKey(request.GetSlaveId(), uint16(request.GetFunction()), request.GetData())
+		serverID := getServerID(server)
+		if err != nil || serverID == -1 {
+			return handler(server, request)
+		}
+		objectType := conf.ObjectTypes.HR
+		if key.FunctionID == conf.Functions.CoilsSimpleWrite || key.FunctionID == conf.Functions.CoilsMultipleWrite {
+			objectType = conf.ObjectTypes.Coils
+		}
+		writtenRange := structs.RegisterRange{SlaveID: key.SlaveID, ObjectType: objectType, Start: key.Address, End: key.Address + max(key.Quantity, 1) - 1}
+		event := writeEvent{
+			Time:       time.Now().String(),
+			ServerID:   serverID,
+			SlaveID:    key.SlaveID,
+			FunctionID: key.FunctionID,
+			ObjectType: objectType,
+			Address:    key.Address,
+			Values:     structs.NewRequestValues(key.FunctionID, request.GetData()),
+		}
+		var exceptionCode uint8
+		emulationServers.readWriteMutex.RLock()
+		event.Policy, exceptionCode = emulationServers.serversData[serverID].WritePolicy.Resolve(writtenRange)
+		emulationServers.readWriteMutex.RUnlock()
+		if event.Policy == conf.WritePolicies.Reject {
+			event.Exception = exceptionCode
+			addWriteEvent(event)
+			rejection := mS.Exception(exceptionCode)
+			return []byte{}, &rejection
+		}
+		if data, exception = handler(server, request); exception != &mS.Success {
+			event.Exception = uint8(*exception)
+		} else if event.Policy == conf.WritePolicies.Keep {
+			keepClientWrite(serverID, server, writtenRange)
+		}
+		pinRegisters(server)
+		addWriteEvent(event)
+		return
+	}
+}
+
+func getServerID(server *mS.Server) int {
+	emulationServers.readWriteMutex.RLock()
+	defer emulationServers.readWriteMutex.RUnlock()
+	return slices.Index(emulationServers.servers, server)
+}
+
+func keepClientWrite(serverID int, server *mS.Server, writtenRange structs.RegisterRange) {
+	slave, ok := server.Slaves[writtenRange.SlaveID]
+	if !ok {
+		return
+	}
+	emulationServers.readWriteMutex.Lock()
+	defer emulationServers.readWriteMutex.Unlock()
+	for currentAddress := int(writtenRange.Start); currentAddress <= int(writtenRange.End); currentAddress++ {
+		address := structs.RegisterAddress{SlaveID: writtenRange.SlaveID, ObjectType: writtenRange.ObjectType, Address: uint16(currentAddress)}
+		if writtenRange.ObjectType == conf.ObjectTypes.Coils {
+			emulationServers.clientWrites[serverID][address] = uint16(slave.Coils[currentAddress])
+		} else {
+			emulationServers.clientWrites[serverID][address] = slave.HoldingRegisters[currentAddress]
+		}
+	}
+}
+
+func pruneClientWrites(serverID int) {
+	for currentAddress := range emulationServers.clientWrites[serverID] {
+		addressRange := structs.RegisterRange{SlaveID: currentAddress.SlaveID, ObjectType: currentAddress.ObjectType, Start: currentAddress.Address, End: currentAddress.Address}
+		if policy, _ := emulationServers.serversData[serverID].WritePolicy.Resolve(addressRange); policy != conf.WritePolicies.Keep {
+			delete(emulationServers.clientWrites[serverID], currentAddress)
+		}
+	}
+}
+
+func pinRegisters(server *mS.Server) {
+	serverID := getServerID(server)
+	if serverID == -1 {
+		return
+	}
+	emulationServers.readWriteMutex.RLock()
+	defer emulationServers.readWriteMutex.RUnlock()
+	for currentAddress, currentValue := range emulationServers.clientWrites[serverID] {
+		setRegister(server, currentAddress, currentValue)
+	}
+	for _, currentForce := range emulationServers.serversData[serverID].Forces {
+		applyForce(server, currentForce)
+	}
+}
+
+func pinEmulationData(server *mS.Server, slaveID uint8, emulationData structs.EmulationData) (pinnedData structs.EmulationData) {
+	pinnedData = emulationData
+	serverID := getServerID(server)
+	objectType := structs.FunctionObjectType(emulationData.FunctionID)
+	if serverID == -1 || objectType == "" {
+		return
+	}
+	emulationServers.readWriteMutex.RLock()
+	defer emulationServers.readWriteMutex.RUnlock()
+	pinnedData.Payload = slices.Clone(emulationData.Payload)
+	for currentIndex := range pinnedData.Payload {
+		address := structs.RegisterAddress{SlaveID: slaveID, ObjectType: objectType, Address: emulationData.Address + uint16(currentIndex)}
+		if currentValue, ok := emulationServers.clientWrites[serverID][address]; ok {
+			pinnedData.Payload[currentIndex] = currentValue
+		}
+		for _, currentForce := range emulationServers.serversData[serverID].Forces {
+			if currentForce.SlaveID != slaveID || currentForce.ObjectType != objectType {
+				continue
+			}
+			if currentValue, ok := currentForce.Value(address.Address); ok {
+				pinnedData.Payload[currentIndex] = currentValue
+			}
+		}
+	}
+	return
+}
+
+func addWriteEvent(event writeEvent) {
+	log.Printf("Client write on %d server: slave %d function %d %s[%d] = %v (policy: %s, exception: %d)",
+		event.ServerID, event.SlaveID, event.FunctionID, event.ObjectType, event.Address, event.Values, event.Policy, event.Exception)
+	emulationServers.readWriteMutex.Lock()
+	defer emulationServers.readWriteMutex.Unlock()
+	events := append(emulationServers.writeEvents[event.ServerID], event)
+	if len(events) > maximalWriteEvents {
+		events = events[len(events)-maximalWriteEvents:]
+	}
+	emulationServers.writeEvents[event.ServerID] = events
+}
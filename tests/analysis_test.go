@@ -0,0 +1,94 @@
+package tests_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeHistory(t *testing.T) {
+	conf.DumpTimeLocation = time.UTC
+	conf.Sockets = map[string]conf.DumpSocketData{
+		"127.0.0.1:1502": {HostAddress: "192.168.1.34", PortAddress: "502", Protocol: conf.Protocols.TCP},
+	}
+	startTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	newEvent := func(request, response []byte, requestOffset, latency time.Duration) (event structs.HistoryEvent) {
+		event.Header = structs.SlaveTransaction{SlaveID: request[6], TransactionID: "0-1"}
+		event.Handshake.RequestUnmarshal(conf.Protocols.TCP, request)
+		event.Handshake.ResponseUnmarshal(conf.Protocols.TCP, response)
+		event.RequestTime = startTime.Add(requestOffset)
+		event.TransactionTime = event.RequestTime.Add(latency)
+		return
+	}
+	readRequest := []byte{0, 1, 0, 0, 0, 6, 1, 3, 0, 100, 0, 2}
+	readResponse := []byte{0, 1, 0, 0, 0, 7, 1, 3, 4, 0, 1, 0, 2}
+	history := map[string]structs.ServerHistory{
+		"127.0.0.1:1502": {
+			Transactions: []structs.HistoryEvent{
+				newEvent(readRequest, readResponse, 0, time.Millisecond),
+				newEvent(readRequest, readResponse, time.Second, 3*time.Millisecond),
+				newEvent(readRequest, readResponse, 2*time.Second, 4*time.Millisecond),
+				newEvent([]byte{0, 2, 0, 0, 0, 6, 2, 4, 0, 7, 0, 1}, []byte{0, 2, 0, 0, 0, 3, 2, 132, 2}, 30*time.Second, 15*time.Millisecond),
+			},
+			Slaves: []uint8{1, 2},
+		},
+	}
+	report, err := ta.AnalyzeHistory(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Equalf(t, 1, len(report.Devices), "Error: recieved and expected devices count isn't equal") {
+		return
+	}
+	device := report.Devices[0]
+	assert.Equalf(t, "192.168.1.34:502", device.DumpSocket, "Error: recieved and expected dump sockets isn't equal")
+	assert.Equalf(t, []ta.FunctionCount{{Function: 3, Count: 3}, {Function: 4, Count: 1}}, device.Functions, "Error: recieved and expected functions isn't equal")
+	assert.Equalf(t, 1, device.Exceptions, "Error: recieved and expected exceptions count isn't equal")
+	expectedSlaves := []ta.SlaveAnalysis{
+		{
+			Slave:                  1,
+			Transactions:           3,
+			Functions:              []ta.FunctionCount{{Function: 3, Count: 3}},
+			AddressRanges:          []ta.AddressRange{{Socket: "127.0.0.1:1502", Slave: 1, ObjectType: "HR", Start: 100, End: 101}},
+			PollCyclePeriodSeconds: 1.001 + 0.0005,
+			Exceptions:             []ta.ExceptionCount{},
+		},
+		{
+			Slave:        2,
+			Transactions: 1,
+			Functions:    []ta.FunctionCount{{Function: 4, Count: 1}},
+			Exceptions:   []ta.ExceptionCount{{Code: 2, Count: 1}},
+		},
+	}
+	assert.InDeltaf(t, expectedSlaves[0].PollCyclePeriodSeconds, device.Slaves[0].PollCyclePeriodSeconds, 1e-9, "Error: recieved and expected poll cycles isn't equal")
+	device.Slaves[0].PollCyclePeriodSeconds = expectedSlaves[0].PollCyclePeriodSeconds
+	assert.Equalf(t, expectedSlaves, device.Slaves, "Error: recieved and expected slaves isn't equal")
+	expectedPercentiles := []ta.LatencyPercentile{
+		{Percentile: 50, Milliseconds: 3}, {Percentile: 90, Milliseconds: 15}, {Percentile: 95, Milliseconds: 15},
+		{Percentile: 99, Milliseconds: 15}, {Percentile: 100, Milliseconds: 15},
+	}
+	assert.Equalf(t, expectedPercentiles, device.LatencyPercentiles, "Error: recieved and expected percentiles isn't equal")
+	assert.Equalf(t, []int{1, 0, 2, 0, 1}, []int{device.LatencyHistogram[0].Count, device.LatencyHistogram[1].Count,
+		device.LatencyHistogram[2].Count, device.LatencyHistogram[3].Count, device.LatencyHistogram[4].Count},
+		"Error: recieved and expected histogram isn't equal")
+	if assert.Equalf(t, 1, len(device.IdleGaps), "Error: recieved and expected idle gaps count isn't equal") {
+		assert.InDeltaf(t, 28.011, device.IdleGaps[0].Seconds, 1e-9, "Error: recieved and expected idle gap isn't equal")
+	}
+
+	var markdownReport, htmlReport bytes.Buffer
+	if err = report.WriteMarkdown(&markdownReport); err != nil {
+		t.Fatal(err)
+	}
+	if err = report.WriteHTML(&htmlReport); err != nil {
+		t.Fatal(err)
+	}
+	assert.Truef(t, strings.Contains(markdownReport.String(), "| 1 | 3 | 1.002 | HR[100:101] |  |"), "Error: markdown report doesn't contain slave row")
+	assert.Truef(t, strings.Contains(htmlReport.String(), "<svg"), "Error: HTML report doesn't contain charts")
+}
@@ -0,0 +1,104 @@
+package tests_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsedHistoryCache(t *testing.T) {
+	directoryPath := t.TempDir()
+	conf.DumpFilePath = fmt.Sprintf("%s/dump", directoryPath)
+	conf.ParsedHistoryCachePath = fmt.Sprintf("%s/parsed_history.cache", directoryPath)
+	conf.Sockets = map[string]conf.DumpSocketData{
+		"127.0.0.1:1502": {
+			HostAddress: "127.0.0.1",
+			PortAddress: "1502",
+			Protocol:    conf.Protocols.TCP,
+		},
+	}
+	if err := os.WriteFile(fmt.Sprintf("%s.pcapng", conf.DumpFilePath), []byte("first dump"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	expectedHistory := map[string]structs.ServerHistory{
+		"127.0.0.1:1502": {
+			Transactions: []structs.HistoryEvent{
+				{
+					Header: structs.SlaveTransaction{SlaveID: 1, TransactionID: "0-1"},
+					Handshake: structs.Handshake{
+						Request: &structs.TCPRequest{
+							Header: structs.MBAPHeader{
+								TransactionID: []byte{0, 1},
+								Protocol:      "modbus",
+								BodyLength:    6,
+								UnitID:        1,
+								FunctionType:  3,
+							},
+							AddressStart: []byte{0, 4},
+							Data:         &structs.TCPReadRequest{NumberReadingBits: []byte{0, 1}},
+						},
+						Response: &structs.TCPResponse{
+							Header: structs.MBAPHeader{
+								TransactionID: []byte{0, 1},
+								Protocol:      "modbus",
+								BodyLength:    5,
+								UnitID:        1,
+								FunctionType:  3,
+							},
+							Data: &structs.TCPReadByteResponse{NumberBits: 2, Data: []byte{0, 42}},
+						},
+					},
+					TransactionTime: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC),
+				},
+			},
+			Slaves: []uint8{1},
+		},
+	}
+	if err := ta.SaveParsedHistoryCache(expectedHistory); err != nil {
+		assert.EqualErrorf(t, err, "nil",
+			"Error: recieved and expected errors isn't equal:\n expected: %s;\n recieved: %s", "nil", err,
+		)
+		return
+	}
+	recievedHistory, err := ta.LoadParsedHistoryCache()
+	if err != nil {
+		assert.EqualErrorf(t, err, "nil",
+			"Error: recieved and expected errors isn't equal:\n expected: %s;\n recieved: %s", "nil", err,
+		)
+		return
+	}
+	recievedEvent := recievedHistory["127.0.0.1:1502"].Transactions[0]
+	expectedEvent := expectedHistory["127.0.0.1:1502"].Transactions[0]
+	assert.Equalf(t, expectedEvent.Header, recievedEvent.Header,
+		"Error: recieved and expected headers isn't equal:\n expected: %+v;\n recieved: %+v", expectedEvent.Header, recievedEvent.Header)
+	assert.Equalf(t, expectedEvent.Handshake, recievedEvent.Handshake,
+		"Error: recieved and expected handshakes isn't equal:\n expected: %+v;\n recieved: %+v", expectedEvent.Handshake, recievedEvent.Handshake)
+	assert.Truef(t, expectedEvent.TransactionTime.Equal(recievedEvent.TransactionTime),
+		"Error: recieved and expected transaction times isn't equal:\n expected: %v;\n recieved: %v", expectedEvent.TransactionTime, recievedEvent.TransactionTime)
+
+	conf.Sockets["127.0.0.1:1502"] = conf.DumpSocketData{
+		HostAddress: "127.0.0.1",
+		PortAddress: "1502",
+		Protocol:    conf.Protocols.RTUOverTCP,
+	}
+	_, err = ta.LoadParsedHistoryCache()
+	assert.Errorf(t, err, "Error: cache must be invalidated after changing of sockets configuration")
+
+	conf.Sockets["127.0.0.1:1502"] = conf.DumpSocketData{
+		HostAddress: "127.0.0.1",
+		PortAddress: "1502",
+		Protocol:    conf.Protocols.TCP,
+	}
+	if err := os.WriteFile(fmt.Sprintf("%s.pcapng", conf.DumpFilePath), []byte("second dump"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ta.LoadParsedHistoryCache()
+	assert.Errorf(t, err, "Error: cache must be invalidated after changing of dump file")
+}
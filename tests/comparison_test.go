@@ -0,0 +1,141 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+	trafficrecording "modbus-emulator/src/traffic_recording"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareHistories(t *testing.T) {
+	conf.Sockets = map[string]conf.DumpSocketData{
+		"127.0.0.1:1502": {HostAddress: "192.168.1.34", PortAddress: "502", Protocol: conf.Protocols.TCP},
+	}
+	startTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	newEntries := func(period time.Duration, values [][]uint16, objectType string, address uint16) (entries []ta.ScenarioEntry) {
+		for currentIndex, currentValues := range values {
+			entries = append(entries, ta.ScenarioEntry{
+				Timestamp:  startTime.Add(time.Duration(currentIndex) * period).Format(time.RFC3339Nano),
+				Slave:      1,
+				ObjectType: objectType,
+				Address:    address,
+				Values:     currentValues,
+			})
+		}
+		return
+	}
+	oldEntries := append(newEntries(time.Second, [][]uint16{{1, 2}, {1, 2}, {1, 2}}, "HR", 100),
+		newEntries(time.Second, [][]uint16{{1, 0, 1}}, "coils", 5)...)
+	newEntriesList := append(newEntries(2*time.Second, [][]uint16{{1, 9}, {1, 9}, {1, 2}}, "HR", 100),
+		newEntries(time.Second, [][]uint16{{7}}, "IR", 3)...)
+	oldHistory, err := ta.BuildScenarioHistory(oldEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newHistory, err := ta.BuildScenarioHistory(newEntriesList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := ta.CompareHistories(oldHistory, newHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedOnlyInOld := []ta.AddressRange{{Socket: "127.0.0.1:1502", Slave: 1, ObjectType: "coils", Start: 5, End: 7}}
+	expectedOnlyInNew := []ta.AddressRange{{Socket: "127.0.0.1:1502", Slave: 1, ObjectType: "IR", Start: 3, End: 3}}
+	assert.Equalf(t, expectedOnlyInOld, report.OnlyInOld, "Error: recieved and expected old ranges isn't equal")
+	assert.Equalf(t, expectedOnlyInNew, report.OnlyInNew, "Error: recieved and expected new ranges isn't equal")
+	if assert.Equalf(t, 1, len(report.DistributionChanges), "Error: recieved and expected distribution changes count isn't equal") {
+		recievedChange := report.DistributionChanges[0]
+		assert.Equalf(t, uint16(101), recievedChange.Address, "Error: recieved and expected changed address isn't equal")
+		assert.InDeltaf(t, 2.0/3, recievedChange.Distance, 1e-9, "Error: recieved and expected distances isn't equal")
+		assert.Equalf(t, ta.ValueStatistics{Samples: 3, Distinct: 2, Min: 2, Max: 9, Mean: 20.0 / 3}, recievedChange.New,
+			"Error: recieved and expected statistics isn't equal")
+	}
+	expectedPollRateChanges := []ta.PollRateChange{{Socket: "127.0.0.1:1502", Slave: 1, Function: 3, Address: 100, Quantity: 2,
+		OldCount: 3, NewCount: 3, OldPeriodSeconds: 1, NewPeriodSeconds: 2}}
+	assert.Equalf(t, expectedPollRateChanges, report.PollRateChanges, "Error: recieved and expected poll rate changes isn't equal")
+
+	var textReport bytes.Buffer
+	if err = report.WriteText(&textReport); err != nil {
+		t.Fatal(err)
+	}
+	for _, currentLine := range []string{
+		"  127.0.0.1:1502 slave 1 coils[5:7]",
+		"  127.0.0.1:1502 slave 1 function 3 [100:101]: period 1.000s -> 2.000s (3 -> 3 requests)",
+	} {
+		assert.Truef(t, strings.Contains(textReport.String(), currentLine), "Error: text report doesn't contain line:\n %s", currentLine)
+	}
+}
+
+func TestCompareDumps(t *testing.T) {
+	workDir := t.TempDir()
+	requestPDU, _ := structs.BuildRequestPDU(3, 100, 2, nil)
+	responsePDU, _ := structs.BuildResponsePDU(3, 100, 2, []uint16{261, 42}, 0)
+	writeDump := func(dumpFilePath string, serverSockets []string) {
+		recorder, err := trafficrecording.NewRecorder(fmt.Sprintf("%s.pcapng", dumpFilePath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		transactionTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+		for currentIndex, currentServerSocket := range serverSockets {
+			if err = recorder.WriteTransaction(fmt.Sprintf("192.168.1.2:%d", 49152+currentIndex), currentServerSocket, transactionTime, transactionTime.Add(10*time.Millisecond),
+				structs.BuildTCPADU(1, 1, requestPDU), structs.BuildTCPADU(1, 1, responsePDU)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = recorder.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeDump(fmt.Sprintf("%s/old", workDir), []string{"192.168.1.34:502"})
+	writeDump(fmt.Sprintf("%s/new", workDir), []string{"192.168.1.34:502", "192.168.1.35:502"})
+	oldWorkMode := conf.WorkMode
+	defer func() { conf.WorkMode = oldWorkMode }()
+	conf.WorkMode = conf.WorkModes.Diff
+	conf.DumpFilePath = fmt.Sprintf("%s/old", workDir)
+	conf.CompareDumpFilePath = fmt.Sprintf("%s/new", workDir)
+	conf.ServerDefaultDumpPort = "502"
+	conf.EmulationPortAddressStart = 1501
+	conf.ServerDefaultEmulateHost = "127.0.0.1"
+	conf.Sockets = make(map[string]conf.DumpSocketData)
+	conf.ReportFormat = conf.ReportFormats.JSON
+	conf.ReportFilePath = fmt.Sprintf("%s/report.json", workDir)
+	defer func() { conf.ReportFilePath = "" }()
+	if err := ta.SocketAutoAccumulation(); err != nil {
+		t.Fatal(err)
+	}
+	var newServerSocket string
+	for currentEmulationSocket, currentDumpSocketData := range conf.Sockets {
+		if currentDumpSocketData.HostAddress == "192.168.1.35" {
+			newServerSocket = currentEmulationSocket
+		}
+	}
+	assert.Equalf(t, 2, len(conf.Sockets), "Error: recieved and expected sockets count isn't equal")
+	if !assert.NotEqualf(t, "", newServerSocket, "Error: server from compared dump must be accumulated") {
+		return
+	}
+	if err := ta.CompareDumps(); err != nil {
+		t.Fatal(err)
+	}
+	reportData, err := os.ReadFile(conf.ReportFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report ta.ComparisonReport
+	if err = json.Unmarshal(reportData, &report); err != nil {
+		t.Fatal(err)
+	}
+	expectedOnlyInNew := []ta.AddressRange{{Socket: newServerSocket, Slave: 1, ObjectType: "HR", Start: 100, End: 101}}
+	assert.Equalf(t, expectedOnlyInNew, report.OnlyInNew, "Error: recieved and expected new ranges isn't equal")
+	assert.Equalf(t, 0, len(report.OnlyInOld), "Error: recieved and expected old ranges count isn't equal")
+}
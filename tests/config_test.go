@@ -0,0 +1,38 @@
+package tests_test
+
+import (
+	"testing"
+
+	"modbus-emulator/conf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlaybackSpeed(t *testing.T) {
+	testCases := []struct {
+		speed          string
+		expectedFactor float64
+		isValid        bool
+	}{
+		{"", 1, true},
+		{"0.1", 0.1, true},
+		{"2.5", 2.5, true},
+		{"100", 100, true},
+		{"max", conf.AsFastAsPossibleFactor, true},
+		{"0.05", 0, false},
+		{"101", 0, false},
+		{"fast", 0, false},
+	}
+	for _, currentCase := range testCases {
+		factor, err := conf.ParsePlaybackSpeed(currentCase.speed)
+		if !currentCase.isValid {
+			assert.Errorf(t, err, "Error: speed %s must be invalid", currentCase.speed)
+			continue
+		}
+		if assert.NoErrorf(t, err, "Error: speed %s must be valid", currentCase.speed) {
+			assert.Equalf(t, currentCase.expectedFactor, factor, "Error: recieved and expected factor of speed %s isn't equal", currentCase.speed)
+			formattedFactor, _ := conf.ParsePlaybackSpeed(conf.FormatPlaybackSpeed(factor))
+			assert.Equalf(t, currentCase.expectedFactor, formattedFactor, "Error: formatted speed %s isn't parsed back", currentCase.speed)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package tests_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabaseHistory(t *testing.T) {
+	directoryPath := t.TempDir()
+	conf.DumpFilePath = fmt.Sprintf("%s/dump", directoryPath)
+	conf.DatabasePath = fmt.Sprintf("%s/history.sqlite", directoryPath)
+	conf.Sockets = map[string]conf.DumpSocketData{
+		"127.0.0.1:1501": {
+			HostAddress: "192.168.1.25",
+			PortAddress: "502",
+			Protocol:    conf.Protocols.RTUOverTCP,
+		},
+	}
+	if err := os.WriteFile(fmt.Sprintf("%s.pcapng", conf.DumpFilePath), []byte("dump"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	startTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	expectedHistory := structs.ServerHistory{
+		Transactions: []structs.HistoryEvent{
+			{
+				Header: structs.SlaveTransaction{SlaveID: 3, TransactionID: "1"},
+				Handshake: structs.Handshake{
+					Request: &structs.RTUOverTCPRequest123456Response56{
+						HeaderError:        structs.HeaderErrorCheck{SlaveAddress: 3, FunctionID: 6, ErrorCheckLow: 1, ErrorCheckHight: 2},
+						StartingAddressLow: 100,
+						ReadWriteDataLow:   42,
+					},
+					Response: &structs.RTUOverTCPRequest123456Response56{
+						HeaderError:        structs.HeaderErrorCheck{SlaveAddress: 3, FunctionID: 6, ErrorCheckLow: 1, ErrorCheckHight: 2},
+						StartingAddressLow: 100,
+						ReadWriteDataLow:   42,
+					},
+				},
+				TransactionTime: startTime,
+				RequestTime:     startTime.Add(-15 * time.Millisecond),
+			},
+			{
+				Header: structs.SlaveTransaction{SlaveID: 3, TransactionID: "2"},
+				Handshake: structs.Handshake{
+					Request: &structs.RTUOverTCPRequest123456Response56{
+						HeaderError:        structs.HeaderErrorCheck{SlaveAddress: 3, FunctionID: 3, ErrorCheckLow: 1, ErrorCheckHight: 2},
+						StartingAddressLow: 100,
+						ReadWriteDataLow:   1,
+					},
+					Response: &structs.RTUOverTCPErrorResponse{
+						HeaderError: structs.HeaderErrorCheck{SlaveAddress: 3, FunctionID: 131, ErrorCheckLow: 1, ErrorCheckHight: 2},
+						ErrorCode:   2,
+					},
+				},
+				TransactionTime: startTime.Add(time.Second),
+			},
+		},
+		Slaves: []uint8{3},
+	}
+	outdatedDatabase, err := sql.Open("sqlite", conf.DatabasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = outdatedDatabase.Exec(`CREATE TABLE transactions (id INTEGER PRIMARY KEY, transaction_time INTEGER NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	outdatedDatabase.Close()
+	if err := ta.SaveHistoryToDatabase(structs.HistorySourcesFromMemory(map[string]structs.ServerHistory{"127.0.0.1:1501": expectedHistory})); err != nil {
+		assert.EqualErrorf(t, err, "nil",
+			"Error: recieved and expected errors isn't equal:\n expected: %s;\n recieved: %s", "nil", err,
+		)
+		return
+	}
+
+	database, err := sql.Open("sqlite", conf.DatabasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	var writtenValue, exceptionCode int
+	if err = database.QueryRow(`SELECT register_values.value FROM register_values
+		JOIN transactions ON transactions.id = register_values.transaction_id
+		WHERE transactions.slave_id = 3 AND transactions.is_read = 0 AND register_values.object_type = 'HR' AND register_values.address = 100`).Scan(&writtenValue); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equalf(t, 42, writtenValue, "Error: recieved and expected written values isn't equal")
+	if err = database.QueryRow(`SELECT exception FROM transactions WHERE position = 1`).Scan(&exceptionCode); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equalf(t, 2, exceptionCode, "Error: recieved and expected exception codes isn't equal")
+	var schemaVersion string
+	if err = database.QueryRow(`SELECT value FROM metadata WHERE key = 'schema_version'`).Scan(&schemaVersion); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equalf(t, "2", schemaVersion, "Error: recieved and expected schema versions isn't equal")
+
+	conf.Sockets = map[string]conf.DumpSocketData{}
+	recievedHistory, err := ta.LoadHistoryFromDatabase()
+	if err != nil {
+		assert.EqualErrorf(t, err, "nil",
+			"Error: recieved and expected errors isn't equal:\n expected: %s;\n recieved: %s", "nil", err,
+		)
+		return
+	}
+	assert.Equalf(t, conf.DumpSocketData{HostAddress: "192.168.1.25", PortAddress: "502", Protocol: conf.Protocols.RTUOverTCP}, conf.Sockets["127.0.0.1:1501"],
+		"Error: sockets must be restored from database")
+	assert.Equalf(t, expectedHistory.Slaves, recievedHistory["127.0.0.1:1501"].Slaves,
+		"Error: recieved and expected slaves isn't equal")
+	for currentIndex, currentExpectedEvent := range expectedHistory.Transactions {
+		currentRecievedEvent := recievedHistory["127.0.0.1:1501"].Transactions[currentIndex]
+		assert.Equalf(t, currentExpectedEvent.Header, currentRecievedEvent.Header,
+			"Error: recieved and expected headers isn't equal")
+		assert.Equalf(t, currentExpectedEvent.Handshake, currentRecievedEvent.Handshake,
+			"Error: recieved and expected handshakes isn't equal:\n expected: %+v;\n recieved: %+v", currentExpectedEvent.Handshake, currentRecievedEvent.Handshake)
+		assert.Truef(t, currentExpectedEvent.TransactionTime.Equal(currentRecievedEvent.TransactionTime),
+			"Error: recieved and expected transaction times isn't equal")
+		assert.Truef(t, currentExpectedEvent.RequestTime.Equal(currentRecievedEvent.RequestTime),
+			"Error: recieved and expected request times isn't equal")
+	}
+}
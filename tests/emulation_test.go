@@ -0,0 +1,459 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src"
+	"modbus-emulator/src/traffic_analysis/structs"
+	trafficrecording "modbus-emulator/src/traffic_recording"
+
+	mc "github.com/goburrow/modbus"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+)
+
+const emulationHTTPSocket = "127.0.0.1:18080"
+
+type (
+	emulationOperation struct {
+		functionID uint16
+		address    uint16
+		values     []uint16
+	}
+	emulationSettings struct {
+		ID       int `json:"id"`
+		Settings struct {
+			IsWorking   bool   `json:"is_working"`
+			IsEmulating bool   `json:"is_emulating"`
+			RealSocket  string `json:"real_socket"`
+			CurrentTime string `json:"current_time"`
+			Loop        struct {
+				Counter int `json:"counter"`
+			} `json:"loop"`
+		} `json:"settings"`
+	}
+	emulationDebug struct {
+		ID          int  `json:"id"`
+		IsEmulating bool `json:"is_emulating"`
+		Debug       struct {
+			StepsLeft        int `json:"steps_left"`
+			TransactionIndex int `json:"transaction_index"`
+			HitBreakpoint    *struct {
+				ID   int    `json:"id"`
+				Kind string `json:"kind"`
+			} `json:"hit_breakpoint"`
+		} `json:"debug"`
+	}
+	emulationRegisters struct {
+		Values []uint16 `json:"values"`
+	}
+)
+
+var (
+	emulationStartTime      = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	emulationHTTPServerOnce sync.Once
+	emulationHTTPClient     = http.Client{Timeout: 5 * time.Second}
+)
+
+func readOperations(values ...uint16) (operations []emulationOperation) {
+	for _, currentValue := range values {
+		operations = append(operations, emulationOperation{conf.Functions.HRRead, 0, []uint16{currentValue}})
+	}
+	return
+}
+
+func newEmulationHistory(t *testing.T, operations []emulationOperation) (history structs.ServerHistory) {
+	for currentIndex, currentOperation := range operations {
+		quantity := uint16(len(currentOperation.values))
+		requestPDU, err := structs.BuildRequestPDU(currentOperation.functionID, currentOperation.address, quantity, currentOperation.values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responsePDU, err := structs.BuildResponsePDU(currentOperation.functionID, currentOperation.address, quantity, currentOperation.values, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var currentEvent structs.HistoryEvent
+		currentEvent.Header = structs.SlaveTransaction{SlaveID: 1, TransactionID: strconv.Itoa(currentIndex + 1)}
+		currentEvent.Handshake.RequestUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), 1, requestPDU))
+		currentEvent.Handshake.ResponseUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), 1, responsePDU))
+		currentEvent.RequestTime = emulationStartTime.Add(time.Duration(currentIndex) * time.Second)
+		currentEvent.TransactionTime = currentEvent.RequestTime
+		history.Transactions = append(history.Transactions, currentEvent)
+	}
+	history.Slaves = []uint8{1}
+	return
+}
+
+func setEmulationConfig(servePath string) {
+	if conf.Sockets == nil {
+		conf.Sockets = make(map[string]conf.DumpSocketData)
+	}
+	conf.Sockets[servePath] = conf.DumpSocketData{HostAddress: "192.168.1.34", PortAddress: "502", Protocol: conf.Protocols.TCP}
+	conf.ServerHTTPServesocket = emulationHTTPSocket
+	conf.DumpTimeLocation = time.UTC
+	conf.SimultaneouslyEmulation = false
+	conf.OneTimeEmulation = false
+	conf.FinishDelayTime = time.Second
+	conf.PlaybackSpeed = 10
+	conf.MaxGapTime = 0
+	conf.KeyframeInterval = 2
+	conf.LoopInTime, conf.LoopOutTime = "", ""
+	conf.ResetPolicy = conf.ResetPolicies.Keep
+	conf.IsPrefillEnabled = false
+	conf.PlaybackMode = conf.PlaybackModes.Time
+	conf.WritePolicy = conf.WritePolicies.Dump
+}
+
+func startEmulation(t *testing.T, servePath string, history structs.ServerHistory) (serverID int) {
+	log.SetOutput(io.Discard)
+	emulationHTTPServerOnce.Do(func() {
+		go src.StartHTTPServer()
+		for currentAttempt := 0; currentAttempt < 50; currentAttempt++ {
+			if connection, err := net.Dial("tcp", emulationHTTPSocket); err == nil {
+				connection.Close()
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	})
+	if src.History == nil {
+		src.History = make(map[string]structs.HistorySource)
+	}
+	src.History[servePath] = &history
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(1)
+	go src.ServerInit(&waitGroup, servePath)
+	serverID = -1
+	assert.Eventuallyf(t, func() bool {
+		var settings []emulationSettings
+		emulationRequest(t, http.MethodGet, "settings", nil, &settings)
+		for _, currentSettings := range settings {
+			if currentSettings.Settings.RealSocket == servePath && currentSettings.Settings.IsWorking {
+				serverID = currentSettings.ID
+			}
+		}
+		return serverID != -1
+	}, 5*time.Second, 50*time.Millisecond, "Error: server %s isn't started", servePath)
+	if serverID == -1 {
+		t.FailNow()
+	}
+	clientHandler := mc.NewTCPClientHandler(servePath)
+	if err := clientHandler.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		emulationRequest(t, http.MethodPost, "debug/pause", url.Values{"server_id": {strconv.Itoa(serverID)}}, nil)
+		clientHandler.Close()
+	})
+	return
+}
+
+func emulationRequest(t *testing.T, method, path string, query url.Values, response any) (statusCode int) {
+	request, err := http.NewRequest(method, fmt.Sprintf("http://%s/modbus-emulator/%s?%s", emulationHTTPSocket, path, query.Encode()), nil)
+	if !assert.NoErrorf(t, err, "Error: request %s %s isn't created", method, path) {
+		return
+	}
+	httpResponse, err := emulationHTTPClient.Do(request)
+	if !assert.NoErrorf(t, err, "Error: request %s %s isn't completed", method, path) {
+		return
+	}
+	defer httpResponse.Body.Close()
+	if response != nil && httpResponse.StatusCode == http.StatusOK {
+		assert.NoErrorf(t, json.NewDecoder(httpResponse.Body).Decode(response), "Error: response of %s %s isn't decoded", method, path)
+	}
+	return httpResponse.StatusCode
+}
+
+func readEmulationRegister(t *testing.T, serverID int, address uint16) (value uint16) {
+	var registers emulationRegisters
+	emulationRequest(t, http.MethodGet, fmt.Sprintf("servers/%d/slaves/1/hr", serverID), url.Values{"from": {strconv.Itoa(int(address))}}, &registers)
+	if len(registers.Values) == 1 {
+		value = registers.Values[0]
+	}
+	return
+}
+
+func readEmulationSettings(t *testing.T, serverID int) (settings emulationSettings) {
+	var response []emulationSettings
+	emulationRequest(t, http.MethodGet, "settings", url.Values{"server_id": {strconv.Itoa(serverID)}}, &response)
+	if len(response) == 1 {
+		settings = response[0]
+	}
+	return
+}
+
+func readEmulationDebug(t *testing.T, serverID int) (debug emulationDebug) {
+	var response []emulationDebug
+	emulationRequest(t, http.MethodGet, "debug", url.Values{"server_id": {strconv.Itoa(serverID)}}, &response)
+	if len(response) == 1 {
+		debug = response[0]
+	}
+	return
+}
+
+func waitEmulationBreakpoint(t *testing.T, serverID int, expectedKind string, expectedIndex int) {
+	assert.Eventuallyf(t, func() bool {
+		debug := readEmulationDebug(t, serverID)
+		return !debug.IsEmulating && debug.Debug.HitBreakpoint != nil && debug.Debug.HitBreakpoint.Kind == expectedKind
+	}, 5*time.Second, 50*time.Millisecond, "Error: %s breakpoint isn't hit", expectedKind)
+	assert.Equalf(t, expectedIndex, readEmulationDebug(t, serverID).Debug.TransactionIndex,
+		"Error: recieved and expected transaction indexes of %s breakpoint isn't equal", expectedKind)
+}
+
+func TestEmulationLoopRewind(t *testing.T) {
+	servePath := "127.0.0.1:1521"
+	setEmulationConfig(servePath)
+	conf.LoopInTime, conf.LoopOutTime = "2024-10-01 12:00:02", "2024-10-01 12:00:05"
+	serverID := startEmulation(t, servePath, newEmulationHistory(t, readOperations(1, 2, 3, 4, 5, 6, 7, 8)))
+	query := url.Values{"server_id": {strconv.Itoa(serverID)}}
+	assert.Eventuallyf(t, func() bool { return readEmulationSettings(t, serverID).Settings.Loop.Counter >= 1 }, 5*time.Second, 50*time.Millisecond,
+		"Error: loop-out point isn't reached")
+	rewindQuery := url.Values{"server_id": {strconv.Itoa(serverID)}, "timepoint": {"2024-10-01 12:00:04"}}
+	for currentAttempt := 0; currentAttempt < 3; currentAttempt++ {
+		assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "time/rewind_emulation", rewindQuery, nil),
+			"Error: recieved and expected rewind status codes isn't equal")
+	}
+	counter := readEmulationSettings(t, serverID).Settings.Loop.Counter
+	assert.Eventuallyf(t, func() bool { return readEmulationSettings(t, serverID).Settings.Loop.Counter > counter }, 5*time.Second, 50*time.Millisecond,
+		"Error: looping isn't continued after rewind")
+	for currentAttempt := 0; currentAttempt < 20; currentAttempt++ {
+		value := readEmulationRegister(t, serverID, 0)
+		assert.Truef(t, value >= 3 && value <= 6, "Error: recieved register value %d is outside of loop segment", value)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/pause", query, nil), "Error: recieved and expected pause status codes isn't equal")
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "time/rewind_emulation", rewindQuery, nil),
+		"Error: recieved and expected rewind status codes isn't equal")
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/step", query, nil), "Error: recieved and expected step status codes isn't equal")
+	assert.Eventuallyf(t, func() bool {
+		debug := readEmulationDebug(t, serverID)
+		return debug.Debug.StepsLeft == 0 && debug.Debug.TransactionIndex == 3
+	}, 5*time.Second, 50*time.Millisecond, "Error: rewinded transaction isn't stepped")
+	assert.Equalf(t, uint16(4), readEmulationRegister(t, serverID, 0), "Error: recieved and expected register values isn't equal")
+}
+
+func TestEmulationPrefill(t *testing.T) {
+	servePath := "127.0.0.1:1522"
+	setEmulationConfig(servePath)
+	conf.IsPrefillEnabled = true
+	operations := []emulationOperation{
+		{conf.Functions.HRRead, 0, []uint16{1}},
+		{conf.Functions.HRRead, 1, []uint16{100}},
+		{conf.Functions.HRRead, 0, []uint16{2}},
+		{conf.Functions.HRRead, 1, []uint16{200}},
+		{conf.Functions.HRRead, 0, []uint16{3}},
+		{conf.Functions.HRRead, 2, []uint16{7}},
+		{conf.Functions.HRRead, 0, []uint16{4}},
+	}
+	serverID := startEmulation(t, servePath, newEmulationHistory(t, operations))
+	query := url.Values{"server_id": {strconv.Itoa(serverID)}}
+	assert.Equalf(t, uint16(7), readEmulationRegister(t, serverID, 2), "Error: recieved and expected prefilled register values isn't equal")
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/pause", query, nil), "Error: recieved and expected pause status codes isn't equal")
+	rewindQuery := url.Values{"server_id": {strconv.Itoa(serverID)}, "timepoint": {"2024-10-01 12:00:03"}}
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "time/rewind_emulation", rewindQuery, nil),
+		"Error: recieved and expected rewind status codes isn't equal")
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/step", query, nil), "Error: recieved and expected step status codes isn't equal")
+	assert.Eventuallyf(t, func() bool {
+		debug := readEmulationDebug(t, serverID)
+		return debug.Debug.StepsLeft == 0 && debug.Debug.TransactionIndex == 2
+	}, 5*time.Second, 50*time.Millisecond, "Error: rewinded transaction isn't stepped")
+	for currentAddress, currentExpectedValue := range []uint16{2, 100, 7} {
+		assert.Equalf(t, currentExpectedValue, readEmulationRegister(t, serverID, uint16(currentAddress)),
+			"Error: recieved and expected values of register %d isn't equal", currentAddress)
+	}
+}
+
+func TestEmulationDebugging(t *testing.T) {
+	servePath := "127.0.0.1:1523"
+	setEmulationConfig(servePath)
+	serverID := startEmulation(t, servePath, newEmulationHistory(t, readOperations(1, 2, 3, 4, 5, 6, 7, 8)))
+	query := url.Values{"server_id": {strconv.Itoa(serverID)}}
+	breakpointQuery := url.Values{"server_id": {strconv.Itoa(serverID)}, "kind": {conf.BreakpointKinds.Register},
+		"slave_id": {"1"}, "object_type": {conf.ObjectTypes.HR}, "address": {"0"}, "value": {"5"}}
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/breakpoints", breakpointQuery, nil),
+		"Error: recieved and expected breakpoint status codes isn't equal")
+	waitEmulationBreakpoint(t, serverID, conf.BreakpointKinds.Register, 4)
+	assert.Equalf(t, uint16(5), readEmulationRegister(t, serverID, 0), "Error: recieved and expected register values isn't equal")
+
+	stepQuery := url.Values{"server_id": {strconv.Itoa(serverID)}, "count": {"2"}}
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/step", stepQuery, nil), "Error: recieved and expected step status codes isn't equal")
+	assert.Eventuallyf(t, func() bool {
+		debug := readEmulationDebug(t, serverID)
+		return debug.Debug.StepsLeft == 0 && debug.Debug.TransactionIndex == 6
+	}, 5*time.Second, 50*time.Millisecond, "Error: transactions aren't stepped")
+	assert.Equalf(t, uint16(7), readEmulationRegister(t, serverID, 0), "Error: recieved and expected register values isn't equal")
+	assert.Falsef(t, readEmulationDebug(t, serverID).IsEmulating, "Error: emulation must stay paused after steps")
+
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodDelete, "debug/breakpoints", query, nil),
+		"Error: recieved and expected breakpoints deleting status codes isn't equal")
+	breakpointQuery = url.Values{"server_id": {strconv.Itoa(serverID)}, "kind": {conf.BreakpointKinds.TransactionIndex}, "transaction_index": {"1"}}
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/breakpoints", breakpointQuery, nil),
+		"Error: recieved and expected breakpoint status codes isn't equal")
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/run", query, nil), "Error: recieved and expected run status codes isn't equal")
+	waitEmulationBreakpoint(t, serverID, conf.BreakpointKinds.TransactionIndex, 1)
+	assert.Equalf(t, uint16(2), readEmulationRegister(t, serverID, 0), "Error: recieved and expected register values isn't equal")
+}
+
+func TestEmulationResetPolicy(t *testing.T) {
+	servePath := "127.0.0.1:1524"
+	setEmulationConfig(servePath)
+	conf.LoopInTime, conf.LoopOutTime = "2024-10-01 12:00:02", "2024-10-01 12:00:03"
+	operations := []emulationOperation{
+		{conf.Functions.HRRead, 0, []uint16{1}},
+		{conf.Functions.HRRead, 1, []uint16{50}},
+		{conf.Functions.HRRead, 0, []uint16{2}},
+		{conf.Functions.HRRead, 1, []uint16{60}},
+		{conf.Functions.HRRead, 0, []uint16{9}},
+	}
+	serverID := startEmulation(t, servePath, newEmulationHistory(t, operations))
+	query := url.Values{"server_id": {strconv.Itoa(serverID)}}
+	breakpointQuery := url.Values{"server_id": {strconv.Itoa(serverID)}, "kind": {conf.BreakpointKinds.TransactionIndex}, "transaction_index": {"2"}}
+	assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/breakpoints", breakpointQuery, nil),
+		"Error: recieved and expected breakpoint status codes isn't equal")
+	waitEmulationBreakpoint(t, serverID, conf.BreakpointKinds.TransactionIndex, 2)
+	for _, currentCase := range []struct {
+		policy        string
+		expectedValue uint16
+	}{
+		{conf.ResetPolicies.Keep, 60},
+		{conf.ResetPolicies.Zero, 0},
+		{conf.ResetPolicies.Snapshot, 50},
+	} {
+		counter := readEmulationSettings(t, serverID).Settings.Loop.Counter
+		policyQuery := url.Values{"server_id": {strconv.Itoa(serverID)}, "policy": {currentCase.policy}}
+		assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "settings/reset_policy", policyQuery, nil),
+			"Error: recieved and expected reset policy status codes isn't equal")
+		assert.Equalf(t, http.StatusOK, emulationRequest(t, http.MethodPost, "debug/run", query, nil), "Error: recieved and expected run status codes isn't equal")
+		waitEmulationBreakpoint(t, serverID, conf.BreakpointKinds.TransactionIndex, 2)
+		assert.Equalf(t, counter+1, readEmulationSettings(t, serverID).Settings.Loop.Counter, "Error: recieved and expected loop counters isn't equal")
+		assert.Equalf(t, uint16(2), readEmulationRegister(t, serverID, 0), "Error: recieved and expected register values isn't equal")
+		assert.Equalf(t, currentCase.expectedValue, readEmulationRegister(t, serverID, 1),
+			"Error: recieved and expected register values after %s reset isn't equal", currentCase.policy)
+	}
+}
+
+func TestEmulationRequestPlayback(t *testing.T) {
+	servePath := "127.0.0.1:1525"
+	setEmulationConfig(servePath)
+	conf.PlaybackMode = conf.PlaybackModes.Request
+	operations := []emulationOperation{
+		{conf.Functions.HRRead, 0, []uint16{1}},
+		{conf.Functions.HRRead, 1, []uint16{2}},
+		{conf.Functions.HRRead, 0, []uint16{3}},
+		{conf.Functions.HRRead, 1, []uint16{4}},
+	}
+	serverID := startEmulation(t, servePath, newEmulationHistory(t, operations))
+	clientHandler := mc.NewTCPClientHandler(servePath)
+	clientHandler.SlaveId, clientHandler.Timeout = 1, 5*time.Second
+	if err := clientHandler.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer clientHandler.Close()
+	client := mc.NewClient(clientHandler)
+	time.Sleep(300 * time.Millisecond)
+	assert.Equalf(t, uint16(0), readEmulationRegister(t, serverID, 0), "Error: playback must not advance without client requests")
+	for _, currentCase := range []struct {
+		address       uint16
+		expectedValue uint16
+		expectedIndex int
+	}{
+		{0, 1, 0},
+		{0, 1, 0},
+		{1, 2, 1},
+		{0, 3, 2},
+		{1, 4, 3},
+	} {
+		response, err := client.ReadHoldingRegisters(currentCase.address, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, []byte{byte(currentCase.expectedValue >> 8), byte(currentCase.expectedValue)}, response,
+			"Error: recieved and expected responses of register %d isn't equal", currentCase.address)
+		assert.Equalf(t, currentCase.expectedValue, readEmulationRegister(t, serverID, currentCase.address),
+			"Error: recieved and expected values of register %d isn't equal", currentCase.address)
+		assert.Equalf(t, currentCase.expectedIndex, readEmulationDebug(t, serverID).Debug.TransactionIndex,
+			"Error: recieved and expected transaction indexes isn't equal")
+	}
+}
+
+func TestEmulationRecording(t *testing.T) {
+	servePath := "127.0.0.1:1526"
+	setEmulationConfig(servePath)
+	recordPath := fmt.Sprintf("%s/record.pcapng", t.TempDir())
+	recorder, err := trafficrecording.NewRecorder(recordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Recorder = recorder
+	defer func() { src.Recorder = nil }()
+	serverID := startEmulation(t, servePath, newEmulationHistory(t, readOperations(1, 2, 3, 4)))
+	var clientSockets []string
+	for currentClient := 0; currentClient < 2; currentClient++ {
+		clientHandler := mc.NewTCPClientHandler(servePath)
+		clientHandler.SlaveId, clientHandler.Timeout = 1, 5*time.Second
+		if err = clientHandler.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = mc.NewClient(clientHandler).ReadHoldingRegisters(0, 1); err != nil {
+			t.Fatal(err)
+		}
+		clientHandler.Close()
+	}
+	emulationRequest(t, http.MethodPost, "debug/pause", url.Values{"server_id": {strconv.Itoa(serverID)}}, nil)
+	if err = recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	recordFile, err := os.Open(recordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recordFile.Close()
+	reader, err := pcapgo.NewNgReader(recordFile, pcapgo.DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestTimes := make(map[string]time.Time)
+	for {
+		data, captureInfo, err := reader.ReadPacketData()
+		if err != nil {
+			break
+		}
+		packet := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+		tcpLayer := packet.Layer(layers.LayerTypeTCP).(*layers.TCP)
+		if len(tcpLayer.Payload) == 0 {
+			continue
+		}
+		if tcpLayer.DstPort == layers.TCPPort(502) {
+			currentSocket := fmt.Sprintf("%s:%d", packet.NetworkLayer().NetworkFlow().Src(), tcpLayer.SrcPort)
+			clientSockets = append(clientSockets, currentSocket)
+			requestTimes[currentSocket] = captureInfo.Timestamp
+		} else {
+			currentSocket := fmt.Sprintf("%s:%d", packet.NetworkLayer().NetworkFlow().Dst(), tcpLayer.DstPort)
+			assert.Falsef(t, captureInfo.Timestamp.Before(requestTimes[currentSocket]), "Error: response of %s is recorded before request", currentSocket)
+		}
+	}
+	if assert.Equalf(t, 2, len(clientSockets), "Error: recieved and expected recorded requests count isn't equal") {
+		assert.NotEqualf(t, clientSockets[0], clientSockets[1], "Error: requests of different clients must be recorded from different sockets")
+		for _, currentSocket := range clientSockets {
+			host, _, err := net.SplitHostPort(currentSocket)
+			assert.NoErrorf(t, err, "Error: recorded client socket %s isn't valid", currentSocket)
+			assert.Equalf(t, "127.0.0.1", host, "Error: recieved and expected client hosts isn't equal")
+		}
+	}
+}
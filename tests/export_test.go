@@ -0,0 +1,74 @@
+package tests_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportHistory(t *testing.T) {
+	conf.DumpTimeLocation = time.UTC
+	conf.ExportFilePath = fmt.Sprintf("%s/export", t.TempDir())
+	conf.Sockets = map[string]conf.DumpSocketData{
+		"127.0.0.1:1502": {
+			HostAddress: "192.168.1.34",
+			PortAddress: "502",
+			Protocol:    conf.Protocols.TCP,
+		},
+	}
+	transactionTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	readEvent := structs.HistoryEvent{Header: structs.SlaveTransaction{SlaveID: 1, TransactionID: "0-1"}, TransactionTime: transactionTime}
+	readEvent.Handshake.RequestUnmarshal(conf.Protocols.TCP, []byte{0, 1, 0, 0, 0, 6, 1, 3, 0, 100, 0, 2})
+	readEvent.Handshake.ResponseUnmarshal(conf.Protocols.TCP, []byte{0, 1, 0, 0, 0, 7, 1, 3, 4, 1, 5, 0, 42})
+	exceptionEvent := structs.HistoryEvent{Header: structs.SlaveTransaction{SlaveID: 1, TransactionID: "0-2"}, TransactionTime: transactionTime.Add(time.Second)}
+	exceptionEvent.Handshake.RequestUnmarshal(conf.Protocols.TCP, []byte{0, 2, 0, 0, 0, 6, 1, 4, 0, 7, 0, 1})
+	exceptionEvent.Handshake.ResponseUnmarshal(conf.Protocols.TCP, []byte{0, 2, 0, 0, 0, 3, 1, 132, 2})
+	history := structs.HistorySourcesFromMemory(map[string]structs.ServerHistory{
+		"127.0.0.1:1502": {Transactions: []structs.HistoryEvent{readEvent, exceptionEvent}, Slaves: []uint8{1}},
+	})
+	testCases := []struct {
+		format       string
+		extension    string
+		expectedFile string
+	}{
+		{
+			conf.ExportFormats.CSV, "csv",
+			"timestamp,socket,dump_socket,slave,function,object_type,address,quantity,values,exception\n" +
+				"2024-10-01T12:00:00Z,127.0.0.1:1502,192.168.1.34:502,1,3,HR,100,2,261;42,0\n" +
+				"2024-10-01T12:00:01Z,127.0.0.1:1502,192.168.1.34:502,1,4,IR,7,1,,2\n",
+		},
+		{
+			conf.ExportFormats.JSONL, "jsonl",
+			`{"timestamp":"2024-10-01T12:00:00Z","socket":"127.0.0.1:1502","dump_socket":"192.168.1.34:502","slave":1,"function":3,"object_type":"HR","address":100,"quantity":2,"values":[261,42],"exception":0}` + "\n" +
+				`{"timestamp":"2024-10-01T12:00:01Z","socket":"127.0.0.1:1502","dump_socket":"192.168.1.34:502","slave":1,"function":4,"object_type":"IR","address":7,"quantity":1,"values":[],"exception":2}` + "\n",
+		},
+		{
+			conf.ExportFormats.Influx, "lp",
+			`modbus_transaction,socket=127.0.0.1:1502,slave=1,function=3,object_type=HR address=100i,quantity=2i,values="261;42",exception=0i 1727784000000000000` + "\n" +
+				`modbus_value,socket=127.0.0.1:1502,slave=1,function=3,object_type=HR,address=100 value=261i 1727784000000000000` + "\n" +
+				`modbus_value,socket=127.0.0.1:1502,slave=1,function=3,object_type=HR,address=101 value=42i 1727784000000000000` + "\n" +
+				`modbus_transaction,socket=127.0.0.1:1502,slave=1,function=4,object_type=IR address=7i,quantity=1i,values="",exception=2i 1727784001000000000` + "\n",
+		},
+	}
+	for _, currentTestCase := range testCases {
+		conf.ExportFormat = currentTestCase.format
+		if err := ta.ExportHistory(history); err != nil {
+			t.Fatal(err)
+		}
+		recievedFile, err := os.ReadFile(fmt.Sprintf("%s.%s", conf.ExportFilePath, currentTestCase.extension))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentTestCase.expectedFile, string(recievedFile),
+			"Error: recieved and expected %s export isn't equal:\n expected: %s;\n recieved: %s", currentTestCase.format,
+			currentTestCase.expectedFile, strings.TrimSpace(string(recievedFile)))
+	}
+}
@@ -0,0 +1,78 @@
+package tests_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	trafficgeneration "modbus-emulator/src/traffic_generation"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateFixture(t *testing.T) {
+	transactions, err := trafficgeneration.ReadTransactions(strings.NewReader(`[
+		{"server": "192.168.1.34:502", "timestamp": "2024-10-01T12:00:00Z", "response_delay": "30ms", "slave": 1, "function": 3, "address": 4, "values": [261]},
+		{"server": "192.168.1.34:502", "timestamp": "2024-10-01T12:00:00.010Z", "response_delay": "5ms", "slave": 1, "function": 4, "address": 7, "quantity": 1, "exception": 2},
+		{"server": "192.168.1.25:502", "protocol": "rtu_over_tcp", "timestamp": "2024-10-01T12:00:00.001Z", "slave": 2, "function": 6, "address": 100, "values": [42], "fragments": 2}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture bytes.Buffer
+	if err = trafficgeneration.Generate(&fixture, transactions); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := pcapgo.NewNgReader(&fixture, pcapgo.DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type recievedPayload struct {
+		host      string
+		timestamp time.Time
+		payload   []byte
+	}
+	var recievedPayloads []recievedPayload
+	for {
+		data, captureInfo, err := reader.ReadPacketData()
+		if err != nil {
+			break
+		}
+		packet := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+		tcpLayer := packet.Layer(layers.LayerTypeTCP).(*layers.TCP)
+		if len(tcpLayer.Payload) == 0 {
+			continue
+		}
+		recievedPayloads = append(recievedPayloads, recievedPayload{
+			packet.NetworkLayer().NetworkFlow().Src().String(), captureInfo.Timestamp, tcpLayer.Payload,
+		})
+	}
+	startTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	expectedPayloads := []recievedPayload{
+		{"192.0.2.1", startTime, []byte{0, 1, 0, 0, 0, 6, 1, 3, 0, 4, 0, 1}},
+		{"192.0.2.1", startTime.Add(time.Millisecond), []byte{2, 6, 0, 100}},
+		{"192.0.2.1", startTime.Add(time.Millisecond), []byte{0, 42, 73, 249}},
+		{"192.168.1.25", startTime.Add(time.Millisecond), []byte{2, 6, 0, 100}},
+		{"192.168.1.25", startTime.Add(time.Millisecond), []byte{0, 42, 73, 249}},
+		{"192.0.2.1", startTime.Add(10 * time.Millisecond), []byte{0, 2, 0, 0, 0, 6, 1, 4, 0, 7, 0, 1}},
+		{"192.168.1.34", startTime.Add(15 * time.Millisecond), []byte{0, 2, 0, 0, 0, 3, 1, 132, 2}},
+		{"192.168.1.34", startTime.Add(30 * time.Millisecond), []byte{0, 1, 0, 0, 0, 5, 1, 3, 2, 1, 5}},
+	}
+	if !assert.Equalf(t, len(expectedPayloads), len(recievedPayloads), "Error: recieved and expected packets count isn't equal") {
+		return
+	}
+	for currentIndex, currentExpected := range expectedPayloads {
+		assert.Equalf(t, currentExpected.host, recievedPayloads[currentIndex].host, "Error: recieved and expected source hosts isn't equal for packet %d", currentIndex)
+		assert.Truef(t, currentExpected.timestamp.Equal(recievedPayloads[currentIndex].timestamp),
+			"Error: recieved and expected timestamps isn't equal for packet %d", currentIndex)
+		assert.Equalf(t, currentExpected.payload, recievedPayloads[currentIndex].payload, "Error: recieved and expected payloads isn't equal for packet %d", currentIndex)
+	}
+
+	_, _, err = trafficgeneration.BuildADUs(trafficgeneration.Transaction{Protocol: conf.Protocols.TCP, FunctionID: 43})
+	assert.Errorf(t, err, "Error: unsupported function must be rejected")
+}
@@ -0,0 +1,99 @@
+package tests_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	historystore "modbus-emulator/src/traffic_analysis/history_store"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryStore(t *testing.T) {
+	storePath := historystore.FilePath(t.TempDir(), "127.0.0.1:1502")
+	startTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	newEvent := func(index int) structs.HistoryEvent {
+		return structs.HistoryEvent{
+			Header: structs.SlaveTransaction{SlaveID: 1, TransactionID: fmt.Sprint(index)},
+			Handshake: structs.Handshake{
+				Request: &structs.TCPRequest{
+					Header:       structs.MBAPHeader{TransactionID: []byte{0, 1}, Protocol: "modbus", BodyLength: 6, UnitID: 1, FunctionType: 3},
+					AddressStart: []byte{0, 4},
+					Data:         &structs.TCPReadRequest{NumberReadingBits: []byte{0, 1}},
+				},
+				Response: &structs.TCPResponse{
+					Header: structs.MBAPHeader{TransactionID: []byte{0, 1}, Protocol: "modbus", BodyLength: 5, UnitID: 1, FunctionType: 3},
+					Data:   &structs.TCPReadByteResponse{NumberBits: 2, Data: []byte{0, byte(index)}},
+				},
+			},
+			TransactionTime: startTime.Add(time.Duration(index) * time.Second),
+		}
+	}
+	writer, err := historystore.Create(storePath, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = writer.Last()
+	assert.Errorf(t, err, "Error: last event of empty store must be rejected")
+	for currentIndex := 0; currentIndex < 10000; currentIndex++ {
+		if err = writer.Append(newEvent(currentIndex)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for currentIndex := 0; currentIndex < 1809; currentIndex++ {
+		if err = writer.RemoveLast(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastEvent, err := writer.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equalf(t, "8190", lastEvent.Header.TransactionID, "Error: recieved and expected last transactions isn't equal")
+	for _, currentIndex := range []int{8191, 8192} {
+		if err = writer.Append(newEvent(currentIndex)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = writer.Finish([]uint8{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = historystore.Open(storePath, "another key")
+	assert.Errorf(t, err, "Error: store must be rejected for another key")
+
+	store, err := historystore.Open(storePath, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	assert.Equalf(t, 8193, store.Len(), "Error: recieved and expected lengths isn't equal")
+	assert.Equalf(t, []uint8{1}, store.GetSlaves(), "Error: recieved and expected slaves isn't equal")
+	for _, currentIndex := range []int{0, 4095, 4096, 8191, 8192, 17} {
+		recievedEvent, err := store.GetEvent(currentIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, fmt.Sprint(currentIndex), recievedEvent.Header.TransactionID,
+			"Error: recieved and expected transactions isn't equal for index %d", currentIndex)
+	}
+	_, err = store.GetEvent(8193)
+	assert.Errorf(t, err, "Error: out of range index must be rejected")
+	testCases := []struct {
+		timepoint     time.Time
+		expectedIndex int
+	}{
+		{startTime, -1},
+		{startTime.Add(500 * time.Millisecond), 0},
+		{startTime.Add(4096 * time.Second), 4095},
+		{startTime.Add(5000*time.Second + time.Millisecond), 5000},
+		{startTime.Add(time.Hour * 24), 8192},
+	}
+	for _, currentTestCase := range testCases {
+		recievedIndex := store.SearchTransaction(currentTestCase.timepoint)
+		assert.Equalf(t, currentTestCase.expectedIndex, recievedIndex,
+			"Error: recieved and expected indices isn't equal:\n expected: %d;\n recieved: %d", currentTestCase.expectedIndex, recievedIndex)
+	}
+}
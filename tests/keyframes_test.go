@@ -0,0 +1,123 @@
+package tests_test
+
+import (
+	"testing"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingHistorySource struct {
+	*structs.ServerHistory
+	events int
+}
+
+func (cHS *countingHistorySource) GetEvent(index int) (structs.HistoryEvent, error) {
+	cHS.events++
+	return cHS.ServerHistory.GetEvent(index)
+}
+
+func TestKeyframesImage(t *testing.T) {
+	operations := []struct {
+		slaveID       uint8
+		functionID    uint16
+		address       uint16
+		values        []uint16
+		exceptionCode uint16
+	}{
+		{1, conf.Functions.HRRead, 0, []uint16{10, 20, 30}, 0},
+		{1, conf.Functions.HRSimpleWrite, 1, []uint16{21}, 0},
+		{2, conf.Functions.IRRead, 5, []uint16{7, 8}, 0},
+		{1, conf.Functions.HRMultipleWrite, 2, []uint16{31, 41}, 0},
+		{1, conf.Functions.HRSimpleWrite, 0, []uint16{99}, 2},
+		{2, conf.Functions.CoilsRead, 0, []uint16{1, 0, 1}, 0},
+		{1, conf.Functions.HRRead, 0, []uint16{11, 22, 33, 44}, 0},
+	}
+	var history structs.ServerHistory
+	for currentIndex, currentOperation := range operations {
+		quantity := uint16(len(currentOperation.values))
+		requestPDU, err := structs.BuildRequestPDU(currentOperation.functionID, currentOperation.address, quantity, currentOperation.values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responsePDU, err := structs.BuildResponsePDU(currentOperation.functionID, currentOperation.address, quantity, currentOperation.values, currentOperation.exceptionCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var currentEvent structs.HistoryEvent
+		currentEvent.Header = structs.SlaveTransaction{SlaveID: currentOperation.slaveID, TransactionID: "0-1"}
+		currentEvent.Handshake.RequestUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), currentOperation.slaveID, requestPDU))
+		currentEvent.Handshake.ResponseUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), currentOperation.slaveID, responsePDU))
+		history.Transactions = append(history.Transactions, currentEvent)
+	}
+	keyframes, err := structs.BuildKeyframes(&history, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equalf(t, 4, len(keyframes.Frames), "Error: recieved and expected keyframes count isn't equal")
+	assert.Equalf(t, 9, len(keyframes.Addresses), "Error: recieved and expected touched addresses count isn't equal")
+	hr := func(address uint16) structs.RegisterAddress {
+		return structs.RegisterAddress{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Address: address}
+	}
+	ir := func(address uint16) structs.RegisterAddress {
+		return structs.RegisterAddress{SlaveID: 2, ObjectType: conf.ObjectTypes.IR, Address: address}
+	}
+	expectedImages := map[int]structs.RegistersImage{
+		0: {},
+		2: {hr(0): 10, hr(1): 21, hr(2): 30},
+		5: {hr(0): 10, hr(1): 21, hr(2): 31, hr(3): 41, ir(5): 7, ir(6): 8},
+	}
+	for currentIndex, currentExpectedImage := range expectedImages {
+		image, err := keyframes.Image(&history, currentIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentExpectedImage, image, "Error: recieved and expected registers image on transaction %d isn't equal", currentIndex)
+	}
+	sequentialKeyframes, err := structs.BuildKeyframes(&history, history.Len())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for currentIndex := 0; currentIndex <= history.Len(); currentIndex++ {
+		image, err := keyframes.Image(&history, currentIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sequentialImage, err := sequentialKeyframes.Image(&history, currentIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, sequentialImage, image, "Error: recieved and expected registers image on transaction %d isn't equal", currentIndex)
+	}
+	coil := func(address uint16) structs.RegisterAddress {
+		return structs.RegisterAddress{SlaveID: 2, ObjectType: conf.ObjectTypes.Coils, Address: address}
+	}
+	expectedLookaheads := map[int]structs.RegistersImage{
+		0: {hr(0): 10, hr(1): 20, hr(2): 30, hr(3): 41, ir(5): 7, ir(6): 8, coil(0): 1, coil(1): 0, coil(2): 1},
+		4: {hr(0): 11, hr(1): 22, hr(2): 33, hr(3): 44, coil(0): 1, coil(1): 0, coil(2): 1},
+		7: {},
+	}
+	for currentIndex, currentExpectedImage := range expectedLookaheads {
+		image, err := keyframes.Lookahead(&history, currentIndex, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentExpectedImage, image, "Error: recieved and expected lookahead image on transaction %d isn't equal", currentIndex)
+	}
+	knownImage, err := keyframes.Image(&history, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &countingHistorySource{ServerHistory: &history}
+	image, err := keyframes.Lookahead(source, 4, knownImage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equalf(t, structs.RegistersImage{coil(0): 1, coil(1): 0, coil(2): 1}, image,
+		"Error: recieved and expected lookahead image of missing registers isn't equal")
+	assert.Equalf(t, 2, source.events, "Error: recieved and expected count of read transactions isn't equal")
+	_, err = keyframes.Image(&history, history.Len()+1)
+	assert.Errorf(t, err, "Error: out of range transaction index must not be restored")
+}
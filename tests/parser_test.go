@@ -1757,6 +1757,7 @@ func TestParsePackets(t *testing.T) {
 									Data: &structs.TCPReadBitResponse{
 										NumberBits: 2,
 										Bits:       146,
+										NextBits:   []byte{0},
 									},
 								},
 							},
@@ -1971,6 +1972,7 @@ func TestParsePackets(t *testing.T) {
 									Data: &structs.TCPReadBitResponse{
 										NumberBits: 2,
 										Bits:       0,
+										NextBits:   []byte{0},
 									},
 								},
 							},
@@ -2185,6 +2187,7 @@ func TestParsePackets(t *testing.T) {
 									Data: &structs.TCPReadBitResponse{
 										NumberBits: 2,
 										Bits:       0,
+										NextBits:   []byte{0},
 									},
 								},
 							},
@@ -2404,6 +2407,7 @@ func TestParsePackets(t *testing.T) {
 									Data: &structs.TCPReadBitResponse{
 										NumberBits: 2,
 										Bits:       146,
+										NextBits:   []byte{0},
 									},
 								},
 							},
@@ -2618,6 +2622,7 @@ func TestParsePackets(t *testing.T) {
 									Data: &structs.TCPReadBitResponse{
 										NumberBits: 2,
 										Bits:       0,
+										NextBits:   []byte{0},
 									},
 								},
 							},
@@ -2832,6 +2837,7 @@ func TestParsePackets(t *testing.T) {
 									Data: &structs.TCPReadBitResponse{
 										NumberBits: 2,
 										Bits:       0,
+										NextBits:   []byte{0},
 									},
 								},
 							},
@@ -0,0 +1,87 @@
+package tests_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"modbus-emulator/src/traffic_analysis/structs"
+	trafficrecording "modbus-emulator/src/traffic_recording"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	recordPath := fmt.Sprintf("%s/record.pcapng", t.TempDir())
+	recorder, err := trafficrecording.NewRecorder(recordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestPDU, _ := structs.BuildRequestPDU(3, 100, 2, nil)
+	responsePDU, _ := structs.BuildResponsePDU(3, 100, 2, []uint16{261, 42}, 0)
+	transactions := [][]byte{
+		structs.BuildTCPADU(1, 1, requestPDU), structs.BuildTCPADU(1, 1, responsePDU),
+		structs.BuildRTUOverTCPADU(1, requestPDU), structs.BuildRTUOverTCPADU(1, responsePDU),
+	}
+	transactionTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	responseTime := transactionTime.Add(15 * time.Millisecond)
+	for currentIndex := 0; currentIndex < len(transactions); currentIndex += 2 {
+		if err = recorder.WriteTransaction("192.0.2.1:49152", "192.168.1.34:502", transactionTime, responseTime, transactions[currentIndex], transactions[currentIndex+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	recordFile, err := os.Open(recordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recordFile.Close()
+	reader, err := pcapgo.NewNgReader(recordFile, pcapgo.DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recievedPayloads [][]byte
+	var packetsCount int
+	for {
+		data, captureInfo, err := reader.ReadPacketData()
+		if err != nil {
+			break
+		}
+		packetsCount++
+		packet := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+		assert.Nilf(t, packet.ErrorLayer(), "Error: recorded packet must be decoded without errors")
+		tcpLayer := packet.Layer(layers.LayerTypeTCP).(*layers.TCP)
+		if len(tcpLayer.Payload) == 0 {
+			assert.Truef(t, transactionTime.Equal(captureInfo.Timestamp), "Error: recieved and expected handshake timestamps isn't equal")
+			continue
+		}
+		expectedPort := layers.TCPPort(502)
+		if len(recievedPayloads)%2 == 0 {
+			assert.Equalf(t, expectedPort, tcpLayer.DstPort, "Error: request must be sent to server port")
+			assert.Truef(t, transactionTime.Equal(captureInfo.Timestamp), "Error: recieved and expected request timestamps isn't equal")
+		} else {
+			assert.Equalf(t, expectedPort, tcpLayer.SrcPort, "Error: response must be sent from server port")
+			assert.Truef(t, responseTime.Equal(captureInfo.Timestamp), "Error: recieved and expected response timestamps isn't equal")
+		}
+		recievedPayloads = append(recievedPayloads, tcpLayer.Payload)
+	}
+	assert.Equalf(t, 7, packetsCount, "Error: recieved and expected packets count isn't equal")
+	assert.Equalf(t, transactions, recievedPayloads, "Error: recieved and expected payloads isn't equal")
+
+	var handshake structs.Handshake
+	handshake.RequestUnmarshal("rtu_over_tcp", recievedPayloads[2])
+	handshake.ResponseUnmarshal("rtu_over_tcp", recievedPayloads[3])
+	recievedData, err := handshake.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := structs.EmulationData{FunctionID: 3, IsReadOperation: true, Address: 100, Quantity: 2, Payload: []uint16{261, 42}}
+	assert.Equalf(t, expectedData, recievedData,
+		"Error: recieved and expected emulation data isn't equal:\n expected: %+v;\n recieved: %+v", expectedData, recievedData)
+}
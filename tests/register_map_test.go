@@ -0,0 +1,123 @@
+package tests_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"modbus-emulator/conf"
+	registermap "modbus-emulator/src/register_map"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferRegisterMap(t *testing.T) {
+	samples := [][]uint16{
+		{0x4148, 0x0000, 0xfffe, 0x0000, 0x4142, 0x4300, 5, 10, 0x0001, 231},
+		{0x415c, 0x0000, 0xffff, 0x0000, 0x4142, 0x4300, 0xfffe, 11, 0x0003, 234},
+		{0x4164, 0x0000, 0x0000, 0x0001, 0x4142, 0x4300, 3, 13, 0x0007, 229},
+		{0x4178, 0x0000, 0x0001, 0x0001, 0x4142, 0x4300, 0xfff0, 13, 0x0005, 236},
+		{0x4181, 0x0000, 0x0004, 0x0001, 0x4142, 0x4300, 0xffff, 20, 0x0004, 240},
+	}
+	var transactions []structs.HistoryEvent
+	for currentIndex, currentValues := range samples {
+		requestPDU, err := structs.BuildRequestPDU(conf.Functions.HRRead, 0, uint16(len(currentValues)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responsePDU, err := structs.BuildResponsePDU(conf.Functions.HRRead, 0, uint16(len(currentValues)), currentValues, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var currentEvent structs.HistoryEvent
+		currentEvent.Header = structs.SlaveTransaction{SlaveID: 1, TransactionID: "0-1"}
+		currentEvent.Handshake.RequestUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), 1, requestPDU))
+		currentEvent.Handshake.ResponseUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), 1, responsePDU))
+		transactions = append(transactions, currentEvent)
+	}
+	registerMap, err := registermap.Infer(map[string]structs.ServerHistory{"127.0.0.1:1502": {Transactions: transactions, Slaves: []uint8{1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTag := func(address uint16, name, tagType string) registermap.Tag {
+		return registermap.Tag{Name: name, Socket: "127.0.0.1:1502", Slave: 1, ObjectType: conf.ObjectTypes.HR, Address: address, Type: tagType}
+	}
+	expectedTags := []registermap.Tag{
+		newTag(0, "slave1_hr0_float32", registermap.Types.Float32),
+		newTag(2, "slave1_hr2_counter", registermap.Types.Uint32),
+		newTag(4, "slave1_hr4_string", registermap.Types.String),
+		newTag(6, "slave1_hr6_int16", registermap.Types.Int16),
+		newTag(7, "slave1_hr7_counter", registermap.Types.Uint16),
+		newTag(8, "slave1_hr8_bitfield", registermap.Types.Bitfield),
+		newTag(9, "slave1_hr9_uint16_scaled", registermap.Types.Uint16),
+	}
+	expectedTags[0].WordOrder = registermap.WordOrders.Big
+	expectedTags[1].WordOrder, expectedTags[1].Counter = registermap.WordOrders.Little, true
+	expectedTags[2].Length = 2
+	expectedTags[4].Counter = true
+	assert.Equalf(t, expectedTags, registerMap.Tags, "Error: recieved and expected register map tags isn't equal")
+	registerMapPath := filepath.Join(t.TempDir(), "register_map.toml")
+	if err = registermap.Save(registerMapPath, registerMap); err != nil {
+		t.Fatal(err)
+	}
+	var savedRegisterMap registermap.RegisterMap
+	if _, err = toml.DecodeFile(registerMapPath, &savedRegisterMap); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equalf(t, registerMap, savedRegisterMap, "Error: recieved and expected saved register map isn't equal")
+}
+
+func TestTagDecode(t *testing.T) {
+	testCases := []struct {
+		tag       registermap.Tag
+		registers []uint16
+		expected  any
+	}{
+		{registermap.Tag{Name: "float32", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Float32}, []uint16{0x4148, 0x0000}, 12.5},
+		{registermap.Tag{Name: "swapped_float32", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Float32, WordOrder: registermap.WordOrders.Little}, []uint16{0x0000, 0x4148}, 12.5},
+		{registermap.Tag{Name: "float64", ObjectType: conf.ObjectTypes.IR, Type: registermap.Types.Float64}, []uint16{0x4029, 0, 0, 0}, 12.5},
+		{registermap.Tag{Name: "uint32", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Uint32, ByteOrder: registermap.ByteOrders.Little}, []uint16{0x0100, 0x0200}, 65538.0},
+		{registermap.Tag{Name: "int16", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Int16, Scale: 0.5, Offset: -40}, []uint16{0xfffe}, -41.0},
+		{registermap.Tag{Name: "string", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.String, Length: 2}, []uint16{0x4142, 0x4300}, "ABC"},
+		{registermap.Tag{Name: "bitfield", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Bitfield}, []uint16{0x0005}, uint16(5)},
+		{registermap.Tag{Name: "bool", ObjectType: conf.ObjectTypes.Coils, Type: registermap.Types.Bool}, []uint16{1}, true},
+	}
+	for _, currentCase := range testCases {
+		if err := currentCase.tag.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		value, err := currentCase.tag.Decode(currentCase.registers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentCase.expected, value, "Error: recieved and expected value of %s tag isn't equal", currentCase.tag.Name)
+	}
+	invalidTag := registermap.Tag{Name: "invalid", ObjectType: conf.ObjectTypes.DI, Type: registermap.Types.Float32}
+	assert.Errorf(t, invalidTag.Validate(), "Error: register type on discrete input must be invalid")
+}
+
+func TestTagEncode(t *testing.T) {
+	testCases := []struct {
+		tag      registermap.Tag
+		value    string
+		expected []uint16
+	}{
+		{registermap.Tag{Name: "Pump1.Speed", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Float32, WordOrder: registermap.WordOrders.Little}, "12.5", []uint16{0x0000, 0x4148}},
+		{registermap.Tag{Name: "Pump1.Temperature", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Int16, Scale: 0.5, Offset: -40}, "-41", []uint16{0xfffe}},
+		{registermap.Tag{Name: "Pump1.Hours", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Uint32, ByteOrder: registermap.ByteOrders.Little}, "65538", []uint16{0x0100, 0x0200}},
+		{registermap.Tag{Name: "Pump1.Model", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.String, Length: 2}, "ABC", []uint16{0x4142, 0x4300}},
+		{registermap.Tag{Name: "Pump1.Alarms", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Bitfield}, "0b101", []uint16{0x0005}},
+		{registermap.Tag{Name: "Pump1.Running", ObjectType: conf.ObjectTypes.Coils, Type: registermap.Types.Bool}, "true", []uint16{1}},
+	}
+	for _, currentCase := range testCases {
+		registers, err := currentCase.tag.Encode(currentCase.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentCase.expected, registers, "Error: recieved and expected registers of %s tag isn't equal", currentCase.tag.Name)
+	}
+	overflowTag := registermap.Tag{Name: "Pump1.Counter", ObjectType: conf.ObjectTypes.HR, Type: registermap.Types.Uint16}
+	_, err := overflowTag.Encode("70000")
+	assert.Errorf(t, err, "Error: out of range value must not be encoded")
+}
@@ -0,0 +1,174 @@
+package tests_test
+
+import (
+	"testing"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePolicyResolve(t *testing.T) {
+	settings := structs.WritePolicySettings{
+		Policy:    conf.WritePolicies.Dump,
+		Exception: 1,
+		Ranges: []structs.WritePolicyRange{
+			{RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 0, End: 99}, Policy: conf.WritePolicies.Keep},
+			{RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 50, End: 59}, Policy: conf.WritePolicies.Reject, Exception: 2},
+			{RegisterRange: structs.RegisterRange{SlaveID: 2, ObjectType: conf.ObjectTypes.Coils, Start: 10, End: 10}, Policy: conf.WritePolicies.Reject},
+		},
+	}
+	testCases := []struct {
+		writtenRange      structs.RegisterRange
+		expectedPolicy    string
+		expectedException uint8
+	}{
+		{structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 0, End: 0}, conf.WritePolicies.Keep, 0},
+		{structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 55, End: 55}, conf.WritePolicies.Reject, 2},
+		{structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 45, End: 50}, conf.WritePolicies.Reject, 2},
+		{structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 59, End: 70}, conf.WritePolicies.Reject, 2},
+		{structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 60, End: 99}, conf.WritePolicies.Keep, 0},
+		{structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 100, End: 110}, conf.WritePolicies.Dump, 1},
+		{structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.Coils, Start: 55, End: 55}, conf.WritePolicies.Dump, 1},
+		{structs.RegisterRange{SlaveID: 2, ObjectType: conf.ObjectTypes.HR, Start: 55, End: 55}, conf.WritePolicies.Dump, 1},
+		{structs.RegisterRange{SlaveID: 2, ObjectType: conf.ObjectTypes.Coils, Start: 5, End: 15}, conf.WritePolicies.Reject, 0},
+		{structs.RegisterRange{SlaveID: 2, ObjectType: conf.ObjectTypes.Coils, Start: 11, End: 15}, conf.WritePolicies.Dump, 1},
+	}
+	for _, currentCase := range testCases {
+		policy, exception := settings.Resolve(currentCase.writtenRange)
+		assert.Equalf(t, currentCase.expectedPolicy, policy, "Error: recieved and expected policies of %+v isn't equal", currentCase.writtenRange)
+		assert.Equalf(t, currentCase.expectedException, exception, "Error: recieved and expected exceptions of %+v isn't equal", currentCase.writtenRange)
+	}
+}
+
+func TestUnforceRange(t *testing.T) {
+	force := structs.ForcedRange{
+		RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 10, End: 14},
+		Values:        []uint16{10, 11, 12, 13, 14},
+	}
+	coilForce := structs.ForcedRange{
+		RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.Coils, Start: 10, End: 11},
+		Values:        []uint16{1, 0},
+	}
+	testCases := []struct {
+		addressRange    structs.RegisterRange
+		remainingForces []structs.ForcedRange
+	}{
+		{
+			structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 0, End: 9},
+			[]structs.ForcedRange{force, coilForce},
+		},
+		{
+			structs.RegisterRange{SlaveID: 2, ObjectType: conf.ObjectTypes.HR, Start: 10, End: 14},
+			[]structs.ForcedRange{force, coilForce},
+		},
+		{
+			structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 0, End: 20},
+			[]structs.ForcedRange{coilForce},
+		},
+		{
+			structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 12, End: 12},
+			[]structs.ForcedRange{
+				{RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 10, End: 11}, Values: []uint16{10, 11}},
+				{RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 13, End: 14}, Values: []uint16{13, 14}},
+				coilForce,
+			},
+		},
+		{
+			structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 5, End: 10},
+			[]structs.ForcedRange{
+				{RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 11, End: 14}, Values: []uint16{11, 12, 13, 14}},
+				coilForce,
+			},
+		},
+		{
+			structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 13, End: 20},
+			[]structs.ForcedRange{
+				{RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 10, End: 12}, Values: []uint16{10, 11, 12}},
+				coilForce,
+			},
+		},
+		{
+			structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.Coils, Start: 11, End: 11},
+			[]structs.ForcedRange{
+				force,
+				{RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.Coils, Start: 10, End: 10}, Values: []uint16{1}},
+			},
+		},
+	}
+	for _, currentCase := range testCases {
+		remainingForces := structs.UnforceRange([]structs.ForcedRange{force, coilForce}, currentCase.addressRange)
+		assert.Equalf(t, currentCase.remainingForces, remainingForces, "Error: recieved and expected forces after unforcing %+v isn't equal", currentCase.addressRange)
+	}
+	assert.Equalf(t, []uint16{10, 11, 12, 13, 14}, force.Values, "Error: recieved and expected values of split force isn't equal")
+}
+
+func TestForcedRangeValue(t *testing.T) {
+	force := structs.ForcedRange{
+		RegisterRange: structs.RegisterRange{SlaveID: 1, ObjectType: conf.ObjectTypes.HR, Start: 10, End: 12},
+		Values:        []uint16{100, 200, 300},
+	}
+	testCases := []struct {
+		address       uint16
+		expectedValue uint16
+		expectedOk    bool
+	}{
+		{9, 0, false},
+		{10, 100, true},
+		{11, 200, true},
+		{12, 300, true},
+		{13, 0, false},
+	}
+	for _, currentCase := range testCases {
+		value, ok := force.Value(currentCase.address)
+		assert.Equalf(t, currentCase.expectedOk, ok, "Error: recieved and expected forcing of %d address isn't equal", currentCase.address)
+		assert.Equalf(t, currentCase.expectedValue, value, "Error: recieved and expected value of %d address isn't equal", currentCase.address)
+	}
+}
+
+func TestCheckRegistersRange(t *testing.T) {
+	testCases := []struct {
+		objectType string
+		from       uint16
+		count      int
+		isValid    bool
+	}{
+		{conf.ObjectTypes.HR, 0, 1, true},
+		{conf.ObjectTypes.HR, 0, 65536, true},
+		{conf.ObjectTypes.HR, 65535, 1, true},
+		{conf.ObjectTypes.HR, 65535, 2, false},
+		{conf.ObjectTypes.Coils, 65530, 10, false},
+		{conf.ObjectTypes.IR, 0, 65537, false},
+		{conf.ObjectTypes.DI, 10, 0, false},
+		{conf.ObjectTypes.DI, 10, -1, false},
+	}
+	for _, currentCase := range testCases {
+		err := structs.CheckRegistersRange(currentCase.objectType, currentCase.from, currentCase.count, 65536)
+		assert.Equalf(t, currentCase.isValid, err == nil, "Error: recieved and expected validity of %s[%d:%d] isn't equal (%v)",
+			currentCase.objectType, currentCase.from, int(currentCase.from)+currentCase.count, err)
+	}
+}
+
+func TestCheckRegistersValues(t *testing.T) {
+	testCases := []struct {
+		objectType string
+		count      int
+		values     []uint16
+		isValid    bool
+	}{
+		{conf.ObjectTypes.HR, 3, []uint16{0, 1000, 65535}, true},
+		{conf.ObjectTypes.IR, 1, []uint16{2}, true},
+		{conf.ObjectTypes.Coils, 4, []uint16{0, 1, 1, 0}, true},
+		{conf.ObjectTypes.DI, 2, []uint16{1, 1}, true},
+		{conf.ObjectTypes.Coils, 2, []uint16{1, 2}, false},
+		{conf.ObjectTypes.DI, 1, []uint16{255}, false},
+		{conf.ObjectTypes.HR, 2, []uint16{1, 2, 3}, false},
+		{conf.ObjectTypes.Coils, 3, []uint16{1}, false},
+	}
+	for _, currentCase := range testCases {
+		err := structs.CheckRegistersValues(currentCase.objectType, currentCase.count, currentCase.values)
+		assert.Equalf(t, currentCase.isValid, err == nil, "Error: recieved and expected validity of %s values %v (count %d) isn't equal (%v)",
+			currentCase.objectType, currentCase.values, currentCase.count, err)
+	}
+}
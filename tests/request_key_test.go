@@ -0,0 +1,51 @@
+package tests_test
+
+import (
+	"testing"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestKey(t *testing.T) {
+	testCases := []struct {
+		functionID uint16
+		address    uint16
+		values     []uint16
+		expected   structs.RequestKey
+	}{
+		{conf.Functions.HRRead, 100, []uint16{1, 2, 3}, structs.RequestKey{SlaveID: 3, FunctionID: conf.Functions.HRRead, Address: 100, Quantity: 3}},
+		{conf.Functions.CoilsRead, 7, []uint16{1, 0}, structs.RequestKey{SlaveID: 3, FunctionID: conf.Functions.CoilsRead, Address: 7, Quantity: 2}},
+		{conf.Functions.HRSimpleWrite, 5, []uint16{500}, structs.RequestKey{SlaveID: 3, FunctionID: conf.Functions.HRSimpleWrite, Address: 5, Quantity: 1}},
+		{conf.Functions.HRMultipleWrite, 10, []uint16{4, 5}, structs.RequestKey{SlaveID: 3, FunctionID: conf.Functions.HRMultipleWrite, Address: 10, Quantity: 2}},
+	}
+	for currentIndex, currentCase := range testCases {
+		quantity := uint16(len(currentCase.values))
+		requestPDU, err := structs.BuildRequestPDU(currentCase.functionID, currentCase.address, quantity, currentCase.values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responsePDU, err := structs.BuildResponsePDU(currentCase.functionID, currentCase.address, quantity, currentCase.values, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var currentEvent structs.HistoryEvent
+		currentEvent.Header = structs.SlaveTransaction{SlaveID: 3, TransactionID: "0-1"}
+		currentEvent.Handshake.RequestUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), 3, requestPDU))
+		currentEvent.Handshake.ResponseUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), 3, responsePDU))
+		recordedKey, err := currentEvent.RequestKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentCase.expected, recordedKey, "Error: recieved and expected recorded request key of function %d isn't equal", currentCase.functionID)
+		liveKey, err := structs.NewRequestKey(3, uint16(requestPDU[0]), requestPDU[1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentCase.expected, liveKey, "Error: recieved and expected live request key of function %d isn't equal", currentCase.functionID)
+	}
+	_, err := structs.NewRequestKey(3, conf.Functions.HRRead, []byte{0, 1})
+	assert.Errorf(t, err, "Error: short request data must not be matched")
+}
@@ -0,0 +1,92 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportScenario(t *testing.T) {
+	conf.ServerDefaultDumpPort = "502"
+	conf.Sockets = map[string]conf.DumpSocketData{
+		"127.0.0.1:1502": {
+			HostAddress: "192.168.1.25",
+			PortAddress: "502",
+			Protocol:    conf.Protocols.TCP,
+		},
+	}
+	csvScenario := `timestamp,socket,slave,object_type,operation,address,values
+2024-10-01T12:00:02Z,192.168.1.25,1,coils,read,10,1;0;1;1;0;0;0;0;1;0;0;0;0;0;0;0;1;1
+2024-10-01T12:00:01Z,192.168.1.25:502,2,HR,write,100,261
+2024-10-01T12:00:03Z,,1,HR,write,5,1 2
+2024-10-01T12:00:04Z,127.0.0.1:1502,1,coils,write,7,1
+`
+	entries, err := ta.ReadCSVScenario(strings.NewReader(csvScenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonEntries, err := ta.ReadJSONScenario(strings.NewReader(`[
+		{"timestamp": "2024-10-01T12:00:05Z", "slave": 1, "object_type": "IR", "address": 0, "values": [7, 65535]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := ta.BuildScenarioHistory(append(entries, jsonEntries...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	startTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	expectedData := []struct {
+		data            structs.EmulationData
+		slaveID         uint8
+		transactionTime time.Time
+	}{
+		{structs.EmulationData{FunctionID: 6, IsReadOperation: false, Address: 100, Quantity: 1, Payload: []uint16{261}}, 2, startTime.Add(time.Second)},
+		{structs.EmulationData{FunctionID: 1, IsReadOperation: true, Address: 10, Quantity: 18, Payload: []uint16{1, 0, 1, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1, 1}}, 1, startTime.Add(2 * time.Second)},
+		{structs.EmulationData{FunctionID: 16, IsReadOperation: false, Address: 5, Quantity: 2, Payload: []uint16{1, 2}}, 1, startTime.Add(3 * time.Second)},
+		{structs.EmulationData{FunctionID: 5, IsReadOperation: false, Address: 7, Quantity: 1, Payload: []uint16{1}}, 1, startTime.Add(4 * time.Second)},
+		{structs.EmulationData{FunctionID: 4, IsReadOperation: true, Address: 0, Quantity: 2, Payload: []uint16{7, 65535}}, 1, startTime.Add(5 * time.Second)},
+	}
+	recievedHistory := history["127.0.0.1:1502"]
+	assert.Equalf(t, []uint8{2, 1}, recievedHistory.Slaves, "Error: recieved and expected slaves isn't equal")
+	if !assert.Equalf(t, len(expectedData), len(recievedHistory.Transactions), "Error: recieved and expected transactions count isn't equal") {
+		return
+	}
+	for currentIndex, currentExpected := range expectedData {
+		currentEvent := recievedHistory.Transactions[currentIndex]
+		recievedData, err := currentEvent.Handshake.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentExpected.data, recievedData,
+			"Error: recieved and expected emulation data isn't equal:\n expected: %+v;\n recieved: %+v", currentExpected.data, recievedData)
+		assert.Equalf(t, currentExpected.slaveID, currentEvent.Header.SlaveID, "Error: recieved and expected slaves isn't equal")
+		assert.Truef(t, currentExpected.transactionTime.Equal(currentEvent.TransactionTime),
+			"Error: recieved and expected transaction times isn't equal:\n expected: %v;\n recieved: %v", currentExpected.transactionTime, currentEvent.TransactionTime)
+	}
+
+	longRow := make([]uint16, 2001)
+	longRow[2000] = 1
+	history, err = ta.BuildScenarioHistory([]ta.ScenarioEntry{{Timestamp: "2024-10-01T12:00:00Z", Socket: "192.168.1.25", Slave: 1, ObjectType: "DI", Values: longRow}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equalf(t, 2, len(history["127.0.0.1:1502"].Transactions), "Error: recieved and expected transactions count of long row isn't equal") {
+		recievedData, err := history["127.0.0.1:1502"].Transactions[1].Handshake.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, structs.EmulationData{FunctionID: 2, IsReadOperation: true, Address: 2000, Quantity: 1, Payload: []uint16{1}}, recievedData,
+			"Error: recieved and expected emulation data of long row isn't equal")
+	}
+	_, err = ta.BuildScenarioHistory([]ta.ScenarioEntry{{Timestamp: "2024-10-01T12:00:00Z", Slave: 1, ObjectType: "DI", Operation: "write", Values: []uint16{1}}})
+	assert.Errorf(t, err, "Error: writing of read-only object must be rejected")
+	_, err = ta.BuildScenarioHistory([]ta.ScenarioEntry{{Timestamp: "yesterday", Slave: 1, ObjectType: "HR", Values: []uint16{1}}})
+	assert.Errorf(t, err, "Error: invalid timestamp must be rejected")
+}
@@ -0,0 +1,91 @@
+package tests_test
+
+import (
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeekTransaction(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var history structs.ServerHistory
+	for _, currentOffset := range []time.Duration{0, 10 * time.Second, 20 * time.Second, 30 * time.Second, 90 * time.Second, 100 * time.Second} {
+		history.Transactions = append(history.Transactions, structs.HistoryEvent{TransactionTime: startTime.Add(currentOffset)})
+	}
+	testCases := []struct {
+		currentIndex int
+		mode         string
+		value        string
+		expected     int
+	}{
+		{0, conf.SeekModes.TransactionIndex, "3", 3},
+		{1, conf.SeekModes.Offset, "+15s", 2},
+		{4, conf.SeekModes.Offset, "-30s", 3},
+		{4, conf.SeekModes.Offset, "-60s", 3},
+		{4, conf.SeekModes.Offset, "-5m", 0},
+		{2, conf.SeekModes.Offset, "+1h", 5},
+		{0, conf.SeekModes.Percentage, "50", 3},
+		{0, conf.SeekModes.Percentage, "100", 5},
+		{5, conf.SeekModes.Percentage, "0", 0},
+		{3, conf.SeekModes.Position, conf.SeekPositions.Start, 0},
+		{3, conf.SeekModes.Position, conf.SeekPositions.End, 5},
+	}
+	for _, currentCase := range testCases {
+		transactionIndex, err := structs.SeekTransaction(&history, currentCase.currentIndex, currentCase.mode, currentCase.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentCase.expected, transactionIndex, "Error: recieved and expected transaction index of %s %s seek isn't equal", currentCase.mode, currentCase.value)
+	}
+	invalidCases := [][2]string{
+		{conf.SeekModes.TransactionIndex, "6"},
+		{conf.SeekModes.Offset, "30"},
+		{conf.SeekModes.Percentage, "101"},
+		{conf.SeekModes.Position, "middle"},
+		{"timepoint", "0"},
+	}
+	for _, currentCase := range invalidCases {
+		_, err := structs.SeekTransaction(&history, 0, currentCase[0], currentCase[1])
+		assert.Errorf(t, err, "Error: %s %s seek must be invalid", currentCase[0], currentCase[1])
+	}
+}
+
+func TestSearchSegment(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var history structs.ServerHistory
+	for _, currentOffset := range []time.Duration{0, 10 * time.Second, 20 * time.Second, 30 * time.Second, 90 * time.Second, 100 * time.Second} {
+		history.Transactions = append(history.Transactions, structs.HistoryEvent{TransactionTime: startTime.Add(currentOffset)})
+	}
+	testCases := []struct {
+		inTime           time.Time
+		outTime          time.Time
+		expectedInIndex  int
+		expectedOutIndex int
+	}{
+		{time.Time{}, time.Time{}, 0, 5},
+		{startTime.Add(5 * time.Second), startTime.Add(60 * time.Second), 1, 3},
+		{startTime.Add(10 * time.Second), startTime.Add(30 * time.Second), 1, 3},
+		{time.Time{}, startTime.Add(time.Hour), 0, 5},
+		{startTime.Add(-time.Hour), time.Time{}, 0, 5},
+	}
+	for _, currentCase := range testCases {
+		inIndex, outIndex, err := structs.SearchSegment(&history, currentCase.inTime, currentCase.outTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, [2]int{currentCase.expectedInIndex, currentCase.expectedOutIndex}, [2]int{inIndex, outIndex}, "Error: recieved and expected loop segment isn't equal")
+	}
+	invalidCases := [][2]time.Time{
+		{startTime.Add(time.Hour), time.Time{}},
+		{time.Time{}, startTime.Add(-time.Hour)},
+		{startTime.Add(40 * time.Second), startTime.Add(50 * time.Second)},
+	}
+	for _, currentCase := range invalidCases {
+		_, _, err := structs.SearchSegment(&history, currentCase[0], currentCase[1])
+		assert.Errorf(t, err, "Error: loop segment [%s:%s] must be invalid", currentCase[0], currentCase[1])
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"modbus-emulator/conf"
 	"modbus-emulator/src"
 	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
 	"sync"
 	"testing"
 	"time"
@@ -142,12 +143,14 @@ func TestServerTCPMode(t *testing.T) {
 			Protocol:    testCasesTCP.workMode,
 		},
 	}
-	if src.History, err = ta.ParseDump(); err != nil {
+	var history map[string]structs.ServerHistory
+	if history, err = ta.ParseDump(); err != nil {
 		assert.EqualErrorf(t, err, "nil",
 			"Error: recieved and expected errors isn't equal:\n expected: %s;\n recieved: %s", "nil", err,
 		)
 		return
 	}
+	src.History = structs.HistorySourcesFromMemory(history)
 	var waitGroup sync.WaitGroup
 	waitGroup.Add(1)
 	go src.ServerInit(&waitGroup, "127.0.0.1:1502")
@@ -314,12 +317,14 @@ func TestServerRTUOverTCPMode(t *testing.T) {
 	}
 	conf.FinishDelayTime = 3 * time.Second
 	conf.OneTimeEmulation = true
-	if src.History, err = ta.ParseDump(); err != nil {
+	var history map[string]structs.ServerHistory
+	if history, err = ta.ParseDump(); err != nil {
 		assert.EqualErrorf(t, err, "nil",
 			"Error: recieved and expected errors isn't equal:\n expected: %s;\n recieved: %s", "nil", err,
 		)
 		return
 	}
+	src.History = structs.HistorySourcesFromMemory(history)
 	var waitGroup sync.WaitGroup
 	waitGroup.Add(1)
 	go src.ServerInit(&waitGroup, socket)
@@ -503,12 +508,14 @@ func TestServerRTUOverTCPMupliplePorts(t *testing.T) {
 	}
 	conf.FinishDelayTime = 3 * time.Second
 	conf.OneTimeEmulation = true
-	if src.History, err = ta.ParseDump(); err != nil {
+	var history map[string]structs.ServerHistory
+	if history, err = ta.ParseDump(); err != nil {
 		assert.EqualErrorf(t, err, "nil",
 			"Error: recieved and expected errors isn't equal:\n expected: %s;\n recieved: %s", "nil", err,
 		)
 		return
 	}
+	src.History = structs.HistorySourcesFromMemory(history)
 	var waitGroup sync.WaitGroup
 	waitGroup.Add(len(testCases))
 	for currentSocket, currentTestCase := range testCases {
@@ -703,12 +710,14 @@ func TestServerTCPMupliplePorts(t *testing.T) {
 	}
 	conf.FinishDelayTime = 5 * time.Second
 	conf.OneTimeEmulation = true
-	if src.History, err = ta.ParseDump(); err != nil {
+	var history map[string]structs.ServerHistory
+	if history, err = ta.ParseDump(); err != nil {
 		assert.EqualErrorf(t, err, "nil",
 			"Error: recieved and expected errors isn't equal:\n expected: %s;\n recieved: %s", "nil", err,
 		)
 		return
 	}
+	src.History = structs.HistorySourcesFromMemory(history)
 	var waitGroup sync.WaitGroup
 	waitGroup.Add(len(testCases))
 	for currentSocket, currentTestCase := range testCases {
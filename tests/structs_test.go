@@ -99,3 +99,28 @@ func TestGetEmulationData(t *testing.T) {
 		)
 	}
 }
+
+func TestBytesToDecimal(t *testing.T) {
+	testCases := []struct {
+		bytes    []byte
+		expected uint16
+	}{
+		{[]byte{0x01, 0x02}, 0x0102},
+		{[]byte{0x00, 0x0f}, 0x000f},
+		{[]byte{0x10, 0x00}, 0x1000},
+		{[]byte{0xab, 0xcd}, 0xabcd},
+		{[]byte{0x05}, 0x0005},
+	}
+	for _, currentCase := range testCases {
+		recievedValue, err := structs.BytesToDecimal(currentCase.bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equalf(t, currentCase.expected, recievedValue, "Error: recieved and expected values of %v isn't equal", currentCase.bytes)
+	}
+	recievedValue, err := structs.BytesToDecimal([]uint16{0x01, 0x02})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equalf(t, uint16(0x0102), recievedValue, "Error: recieved and expected values of uint16 bytes isn't equal")
+}
@@ -0,0 +1,95 @@
+package tests_test
+
+import (
+	"testing"
+	"time"
+
+	"modbus-emulator/conf"
+	ta "modbus-emulator/src/traffic_analysis"
+	"modbus-emulator/src/traffic_analysis/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifier(t *testing.T) {
+	operations := []struct {
+		functionID uint16
+		address    uint16
+		values     []uint16
+	}{
+		{conf.Functions.HRRead, 0, []uint16{1, 2}},
+		{conf.Functions.HRSimpleWrite, 5, []uint16{500}},
+		{conf.Functions.CoilsMultipleWrite, 8, []uint16{1, 0, 1}},
+		{conf.Functions.IRRead, 10, []uint16{3}},
+		{conf.Functions.HRMultipleWrite, 20, []uint16{7, 8}},
+	}
+	var history structs.ServerHistory
+	var requests [][]byte
+	for currentIndex, currentOperation := range operations {
+		quantity := uint16(len(currentOperation.values))
+		requestPDU, err := structs.BuildRequestPDU(currentOperation.functionID, currentOperation.address, quantity, currentOperation.values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responsePDU, err := structs.BuildResponsePDU(currentOperation.functionID, currentOperation.address, quantity, currentOperation.values, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var currentEvent structs.HistoryEvent
+		currentEvent.Header = structs.SlaveTransaction{SlaveID: 1, TransactionID: "0-1"}
+		currentEvent.Handshake.RequestUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), 1, requestPDU))
+		currentEvent.Handshake.ResponseUnmarshal(conf.Protocols.TCP, structs.BuildTCPADU(uint16(currentIndex+1), 1, responsePDU))
+		history.Transactions = append(history.Transactions, currentEvent)
+		requests = append(requests, requestPDU)
+	}
+	newVerifier := func() *ta.Verifier {
+		verifier, err := ta.NewVerifier(map[string]structs.HistorySource{"127.0.0.1:1502": &history})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return verifier
+	}
+	check := func(verifier *ta.Verifier, requestPDU []byte) *ta.Deviation {
+		key, err := structs.NewRequestKey(1, uint16(requestPDU[0]), requestPDU[1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return verifier.Check("127.0.0.1:1502", ta.VerifiedRequest{RequestKey: key, Values: structs.NewRequestValues(key.FunctionID, requestPDU[1:])}, time.Now())
+	}
+	verifier := newVerifier()
+	for _, currentRequest := range requests {
+		assert.Nilf(t, check(verifier, currentRequest), "Error: recorded request must not be deviation")
+	}
+	verifier.Finish("127.0.0.1:1502")
+	report := verifier.Report()
+	assert.Truef(t, report.Passed && report.IsFinished, "Error: verification of recorded requests must pass")
+	assert.Equalf(t, 5, report.Servers[0].Matched, "Error: recieved and expected matched requests count isn't equal")
+
+	verifier = newVerifier()
+	changedWrite, err := structs.BuildRequestPDU(conf.Functions.HRSimpleWrite, 5, 1, []uint16{501})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unexpectedRead, err := structs.BuildRequestPDU(conf.Functions.HRRead, 100, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deviations := []*ta.Deviation{
+		check(verifier, requests[0]),
+		check(verifier, changedWrite),
+		check(verifier, unexpectedRead),
+		check(verifier, requests[3]),
+	}
+	assert.Nilf(t, deviations[0], "Error: recorded request must not be deviation")
+	var kinds []string
+	for _, currentDeviation := range deviations[1:] {
+		if assert.NotNilf(t, currentDeviation, "Error: changed request must be deviation") {
+			kinds = append(kinds, currentDeviation.Kind)
+		}
+	}
+	assert.Equalf(t, []string{conf.DeviationKinds.Values, conf.DeviationKinds.Unexpected, conf.DeviationKinds.Order}, kinds, "Error: recieved and expected deviation kinds isn't equal")
+	assert.Equalf(t, 1, deviations[3].Skipped, "Error: recieved and expected skipped requests count isn't equal")
+	report = verifier.Report()
+	assert.Falsef(t, report.Passed || report.IsFinished, "Error: verification with deviations must fail")
+	assert.Equalf(t, 1, report.Servers[0].Missing, "Error: recieved and expected missing requests count isn't equal")
+}
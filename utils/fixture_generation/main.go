@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	trafficgeneration "modbus-emulator/src/traffic_generation"
+)
+
+func main() {
+	log.SetFlags(0)
+	inputPath := flag.String("input", "transactions.json", "path to JSON list of transactions")
+	outputPath := flag.String("output", "fixture.pcapng", "path to generated pcapng file")
+	flag.Parse()
+	inputFile, err := os.Open(*inputPath)
+	if err != nil {
+		log.Fatalf("Error on opening transactions file: %s", err)
+	}
+	defer inputFile.Close()
+	var transactions []trafficgeneration.Transaction
+	if transactions, err = trafficgeneration.ReadTransactions(inputFile); err != nil {
+		log.Fatalf("Error on reading transactions: %s", err)
+	}
+	if err = trafficgeneration.GenerateFile(*outputPath, transactions); err != nil {
+		log.Fatalf("Error on generating fixture: %s", err)
+	}
+	log.Printf("%d transactions successfully written to \"%s\"", len(transactions), *outputPath)
+}